@@ -0,0 +1,221 @@
+package gotemplate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// splitWords splits on anything but letters and digits and on case changes:
+// "HTMLParser_v2 test" => HTML Parser v2 test
+func splitWords(s string) []string {
+	words := []string{}
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		lowerToUpper := unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev))
+		acronymEnd := unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+func capitalise(w string) string {
+	r := []rune(strings.ToLower(w))
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
+
+// camelCase: "order line item" => orderLineItem
+func camelCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = capitalise(w)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// pascalCase: "order line item" => OrderLineItem
+func pascalCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = capitalise(w)
+	}
+	return strings.Join(words, "")
+}
+
+func joinWords(s, sep string, upper bool) string {
+	words := splitWords(s)
+	for i, w := range words {
+		if upper {
+			words[i] = strings.ToUpper(w)
+		} else {
+			words[i] = strings.ToLower(w)
+		}
+	}
+	return strings.Join(words, sep)
+}
+
+// snakeCase: "OrderLineItem" => order_line_item
+func snakeCase(s string) string {
+	return joinWords(s, "_", false)
+}
+
+// kebabCase: "OrderLineItem" => order-line-item
+func kebabCase(s string) string {
+	return joinWords(s, "-", false)
+}
+
+// screamingSnake: "orderLineItem" => ORDER_LINE_ITEM
+func screamingSnake(s string) string {
+	return joinWords(s, "_", true)
+}
+
+// titleCase capitalises words by the rules of the locale (or the default
+// locale): titleCase "nl" "ijsselmeer" => IJsselmeer
+func titleCase(args ...string) (string, error) {
+	return titleCaseIn("", args...)
+}
+
+func titleCaseIn(locale string, args ...string) (string, error) {
+	var s string
+	switch len(args) {
+	case 1:
+		s = args[0]
+	case 2:
+		locale, s = args[0], args[1]
+	default:
+		return "", fmt.Errorf("titleCase: expected [locale] string")
+	}
+	if locale == "" {
+		locale = getDefaultLocale()
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", fmt.Errorf("titleCase: %v", err)
+	}
+	return cases.Title(tag).String(s), nil
+}
+
+// transliterations of letters that don't decompose to ASCII
+var transliterations = map[rune]string{
+	'ß': "ss", 'ẞ': "SS", 'Æ': "AE", 'æ': "ae", 'Ø': "O", 'ø': "o", 'Œ': "OE", 'œ': "oe",
+	'Đ': "D", 'đ': "d", 'Ð': "D", 'ð': "d", 'Ł': "L", 'ł': "l", 'Þ': "TH", 'þ': "th",
+	'ı': "i", 'ħ': "h", 'Ħ': "H",
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "Yo", 'Ж': "Zh",
+	'З': "Z", 'И': "I", 'Й': "Y", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N", 'О': "O",
+	'П': "P", 'Р': "R", 'С': "S", 'Т': "T", 'У': "U", 'Ф': "F", 'Х': "Kh", 'Ц': "Ts",
+	'Ч': "Ch", 'Ш': "Sh", 'Щ': "Shch", 'Ъ': "", 'Ы': "Y", 'Ь': "", 'Э': "E", 'Ю': "Yu",
+	'Я': "Ya",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// language specific transliterations, used before the general ones
+var langTransliterations = map[string]map[rune]string{
+	"de": {'Ä': "Ae", 'Ö': "Oe", 'Ü': "Ue", 'ä': "ae", 'ö': "oe", 'ü': "ue"},
+	"da": {'Å': "Aa", 'å': "aa", 'Ø': "Oe", 'ø': "oe"},
+	"nb": {'Å': "Aa", 'å': "aa", 'Ø': "Oe", 'ø': "oe"},
+}
+
+// transliterate turns s into ASCII: language rules, the transliteration
+// table, then accents are dropped; other characters are removed
+func transliterate(s, lang string) string {
+	specific := langTransliterations[strings.Split(normalizeLocale(lang), "-")[0]]
+	var b strings.Builder
+	for _, r := range s {
+		if t, ok := specific[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		if t, ok := transliterations[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		for _, d := range norm.NFD.String(string(r)) {
+			if d < unicode.MaxASCII {
+				b.WriteRune(d)
+			} else if unicode.IsSpace(d) {
+				b.WriteRune(' ')
+			}
+		}
+	}
+	return b.String()
+}
+
+// slugify makes an ASCII URL slug, options: "lang=de" for language rules
+// (Müller => mueller), "sep=_" for the separator, "max=40" for the maximum
+// length: slugify "lang=de" "max=40" .name
+func slugify(args ...interface{}) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("slugify: expected [options] string")
+	}
+	opts := stringArgs(args)
+	s := opts[len(opts)-1]
+	lang, sep, max := "", "-", 0
+	for _, opt := range opts[:len(opts)-1] {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return "", fmt.Errorf("slugify: unknown option %s", opt)
+		}
+		switch kv[0] {
+		case "lang":
+			lang = kv[1]
+		case "sep":
+			sep = kv[1]
+		case "max":
+			n, err := strconv.Atoi(kv[1])
+			if err != nil {
+				return "", fmt.Errorf("slugify: invalid max %s", kv[1])
+			}
+			max = n
+		default:
+			return "", fmt.Errorf("slugify: unknown option %s", opt)
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(transliterate(s, lang)), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	slug := strings.Join(words, sep)
+	if max > 0 && len(slug) > max {
+		// cut at a word boundary when there is one
+		cut := slug[:max]
+		if i := strings.LastIndex(cut, sep); i > 0 && sep != "" && !strings.HasPrefix(slug[max:], sep) {
+			cut = cut[:i]
+		}
+		slug = strings.TrimSuffix(cut, sep)
+	}
+	return slug, nil
+}
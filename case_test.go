@@ -0,0 +1,42 @@
+package gotemplate
+
+import (
+	"testing"
+)
+
+func TestCase(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"case": {
+			Template: `{{ camelCase "order line_item" }}|{{ pascalCase "order-line item" }}|{{ snakeCase "OrderLineItem" }}|{{ kebabCase "HTMLParser v2" }}|{{ screamingSnake "orderLineItem" }}|{{ camelCase "ÜberGröße" }}`,
+			Result:   "orderLineItem|OrderLineItem|order_line_item|html-parser-v2|ORDER_LINE_ITEM|überGröße",
+		},
+		"titleCase": {
+			Template: `{{ titleCase "the qUICK brown fox" }}|{{ titleCase "nl" "ijsselmeer" }}|{{ titleCase "tr" "istanbul" }}`,
+			Result:   "The Quick Brown Fox|IJsselmeer|İstanbul",
+		},
+		"slugify": {
+			Template: `{{ slugify "Café Crème" }}|{{ slugify "lang=de" "Müller & Söhne GmbH" }}|{{ slugify "Müller & Söhne GmbH" }}|{{ slugify "sep=_" "Straße 12, Øresund" }}|{{ slugify "Москва" }}`,
+			Result:   "cafe-creme|mueller-soehne-gmbh|muller-sohne-gmbh|strasse_12_oresund|moskva",
+		},
+		"slugify max": {
+			Template: `{{ slugify "max=20" "The quick brown fox jumps" }}|{{ slugify "max=9" "The quick brown" }}|{{ slugify "max=5" "Extraordinary" }}`,
+			Result:   "the-quick-brown-fox|the-quick|extra",
+		},
+		"url_path": {
+			Template: `{{ url_path "Café Crème - Special!" }}`,
+			Result:   "cafe-creme-special",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := slugify("size=3", "x"); err == nil {
+		t.Errorf("slugify: expected error for unknown option")
+	}
+}
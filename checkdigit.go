@@ -0,0 +1,218 @@
+package gotemplate
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// cleanCode removes spaces and hyphens and upper cases a code
+func cleanCode(v interface{}) string {
+	s := fmt.Sprint(v)
+	if f, ok := v.(float64); ok {
+		// codes from JSON
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	s = strings.ToUpper(s)
+	return strings.NewReplacer(" ", "", "-", "", "\u00a0", "").Replace(s)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// gs1Digit is the GS1 mod-10 check digit of digits, weighting 3 and 1 from
+// the right
+func gs1Digit(digits string) int {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// gs1CheckDigit computes the GS1 check digit of a code without it:
+// gs1CheckDigit "400638133393" => 1
+func gs1CheckDigit(code interface{}) (int, error) {
+	s := cleanCode(code)
+	if !isDigits(s) || len(s) > 17 {
+		return 0, fmt.Errorf("gs1CheckDigit: invalid code %v", code)
+	}
+	return gs1Digit(s), nil
+}
+
+// gs1Complete appends the GS1 check digit: gs1Complete "400638133393" => 4006381333931
+func gs1Complete(code interface{}) (string, error) {
+	d, err := gs1CheckDigit(code)
+	if err != nil {
+		return "", err
+	}
+	return cleanCode(code) + strconv.Itoa(d), nil
+}
+
+// isGS1 validates EAN-8, UPC-A, EAN-13, GTIN-14 and SSCC codes by length
+// and check digit
+func isGS1(code interface{}) bool {
+	s := cleanCode(code)
+	switch len(s) {
+	case 8, 12, 13, 14, 17, 18:
+		return isDigits(s) && gs1Digit(s[:len(s)-1]) == int(s[len(s)-1]-'0')
+	}
+	return false
+}
+
+// isISBN validates ISBN-10 and ISBN-13 numbers, hyphens allowed
+func isISBN(code interface{}) bool {
+	s := cleanCode(code)
+	switch len(s) {
+	case 10:
+		return isDigits(s[:9]) && isbn10Digit(s[:9]) == s[9:]
+	case 13:
+		return (strings.HasPrefix(s, "978") || strings.HasPrefix(s, "979")) && isGS1(s)
+	}
+	return false
+}
+
+func isbn10Digit(digits string) string {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(digits[i]-'0') * (10 - i)
+	}
+	d := (11 - sum%11) % 11
+	if d == 10 {
+		return "X"
+	}
+	return strconv.Itoa(d)
+}
+
+// isbn13 converts an ISBN-10 to ISBN-13, ISBN-13s are returned cleaned
+func isbn13(code interface{}) (string, error) {
+	if !isISBN(code) {
+		return "", fmt.Errorf("isbn13: invalid ISBN %v", code)
+	}
+	s := cleanCode(code)
+	if len(s) == 13 {
+		return s, nil
+	}
+	return gs1Complete("978" + s[:9])
+}
+
+func luhnSum(digits string, double bool) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum
+}
+
+// isLuhn validates a Luhn (mod 10) checked number like a card number
+func isLuhn(code interface{}) bool {
+	s := cleanCode(code)
+	return len(s) > 1 && isDigits(s) && luhnSum(s, false)%10 == 0
+}
+
+// luhnCheckDigit computes the Luhn check digit to append to a number
+func luhnCheckDigit(code interface{}) (int, error) {
+	s := cleanCode(code)
+	if !isDigits(s) {
+		return 0, fmt.Errorf("luhnCheckDigit: invalid number %v", code)
+	}
+	return (10 - luhnSum(s, true)%10) % 10, nil
+}
+
+// ibanLengths by country code, from the SWIFT IBAN registry
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BI": 27, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24,
+	"DE": 22, "DJ": 27, "DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18,
+	"FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27,
+	"GT": 28, "HN": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26,
+	"IT": 27, "JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20,
+	"LU": 20, "LV": 21, "LY": 25, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20,
+	"MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18, "NO": 15, "OM": 23, "PK": 24,
+	"PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33, "SA": 24,
+	"SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25,
+	"SV": 28, "TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+	"YE": 30,
+}
+
+// isIBAN validates the country length and mod-97 checksum of an IBAN
+func isIBAN(code interface{}) bool {
+	s := cleanCode(code)
+	if len(s) < 5 || ibanLengths[s[:2]] != len(s) {
+		return false
+	}
+	var b strings.Builder
+	for _, r := range s[4:] + s[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			b.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(b.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// formatIBAN validates an IBAN and writes it in groups of four:
+// formatIBAN "gb82wEST12345698765432" => GB82 WEST 1234 5698 7654 32
+func formatIBAN(code interface{}) (string, error) {
+	if !isIBAN(code) {
+		return "", fmt.Errorf("formatIBAN: invalid IBAN %v", code)
+	}
+	s := cleanCode(code)
+	groups := []string{}
+	for len(s) > 4 {
+		groups = append(groups, s[:4])
+		s = s[4:]
+	}
+	return strings.Join(append(groups, s), " "), nil
+}
+
+// isUKVAT validates UK VAT numbers with or without the GB prefix: 9 digits
+// with the mod 97 or 9755 check, 12 digit branch numbers, and GD/HA
+// government and health authority numbers
+func isUKVAT(code interface{}) bool {
+	s := strings.TrimPrefix(cleanCode(code), "GB")
+	if len(s) == 5 && (strings.HasPrefix(s, "GD") || strings.HasPrefix(s, "HA")) && isDigits(s[2:]) {
+		n, _ := strconv.Atoi(s[2:])
+		return strings.HasPrefix(s, "GD") && n < 500 || strings.HasPrefix(s, "HA") && n >= 500
+	}
+	if len(s) == 12 {
+		s = s[:9]
+	}
+	if len(s) != 9 || !isDigits(s) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 7; i++ {
+		sum += int(s[i]-'0') * (8 - i)
+	}
+	check, _ := strconv.Atoi(s[7:])
+	total := sum + check
+	return total%97 == 0 || (total+55)%97 == 0
+}
@@ -0,0 +1,49 @@
+package gotemplate
+
+import (
+	"testing"
+)
+
+func TestCheckDigits(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"gs1": {
+			Template: `{{ gs1CheckDigit "400638133393" }}|{{ gs1Complete "03600029145" }}|{{ isGS1 "4006381333931" }}|{{ isGS1 .ean }}|{{ isGS1 "4006381333932" }}|{{ isGS1 "00012345600012" }}|{{ isGS1 "123" }}`,
+			Values:   map[string]interface{}{"ean": float64(4006381333931)},
+			Result:   "1|036000291452|true|true|false|true|false",
+		},
+		"isbn": {
+			Template: `{{ isISBN "0-306-40615-2" }}|{{ isISBN "0-306-40615-3" }}|{{ isISBN "080442957X" }}|{{ isISBN "978-0-306-40615-7" }}|{{ isbn13 "0-306-40615-2" }}`,
+			Result:   "true|false|true|true|9780306406157",
+		},
+		"luhn": {
+			Template: `{{ isLuhn "4111 1111 1111 1111" }}|{{ isLuhn "4111111111111112" }}|{{ luhnCheckDigit "7992739871" }}`,
+			Result:   "true|false|3",
+		},
+		"iban": {
+			Template: `{{ isIBAN "GB82 WEST 1234 5698 7654 32" }}|{{ isIBAN "DE89370400440532013000" }}|{{ isIBAN "DE89370400440532013001" }}|{{ isIBAN "XX82WEST12345698765432" }}|{{ formatIBAN "gb82west12345698765432" }}`,
+			Result:   "true|true|false|false|GB82 WEST 1234 5698 7654 32",
+		},
+		"iban registry": {
+			Template: `{{ isIBAN "BR18 0036 0305 0000 1000 9795 493C 1" }}|{{ isIBAN "UA21 3223 1300 0002 6007 2335 6600 1" }}|{{ isIBAN "RS35 2600 0560 1001 6113 79" }}|{{ isIBAN "BR18003603050000100097954931" }}`,
+			Result:   "true|true|true|false",
+		},
+		"uk vat": {
+			Template: `{{ isUKVAT "GB 980 7806 84" }}|{{ isUKVAT "434031494" }}|{{ isUKVAT "980780685" }}|{{ isUKVAT "GB980780684001" }}|{{ isUKVAT "GBGD001" }}|{{ isUKVAT "HA499" }}`,
+			Result:   "true|true|false|true|true|false",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	for _, tmpl := range []string{`{{ formatIBAN "GB00" }}`, `{{ gs1CheckDigit "12a" }}`, `{{ isbn13 "123" }}`} {
+		if _, err := Template(tmpl, nil); err == nil {
+			t.Errorf("%s: expected error", tmpl)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package gotemplate
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time to the time based template funcs
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+var clock Clock = realClock{}
+
+// RegisterClock sets the clock used by the time based template funcs,
+// nil restores the system clock
+func RegisterClock(c Clock) {
+	flock.Lock()
+	defer flock.Unlock()
+	if c == nil {
+		c = realClock{}
+	}
+	clock = c
+}
+
+func getClock() Clock {
+	flock.Lock()
+	defer flock.Unlock()
+	return clock
+}
+
+// FakeClock is a Clock for reproducible output, it stands still or moves on
+// by a fixed step each time it's read
+type FakeClock struct {
+	mu   sync.Mutex
+	t    time.Time
+	step time.Duration
+}
+
+// NewFakeClock returns a clock at t that advances by step after each Now,
+// a zero step keeps it fixed
+func NewFakeClock(t time.Time, step time.Duration) *FakeClock {
+	return &FakeClock{t: t, step: step}
+}
+
+// Now returns the fake time and advances it by the step
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.t
+	c.t = c.t.Add(c.step)
+	return t
+}
+
+// Set moves the clock to t
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+// Advance moves the clock on by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
@@ -0,0 +1,93 @@
+// Command i18nkeys lists the message keys used with t in templates, or with
+// -catalog the keys missing from the given catalogs:
+//
+//	i18nkeys templates/*.tmpl
+//	i18nkeys -catalog de=i18n/de.json -catalog fr=i18n/fr.po templates/*.tmpl
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gpmd/gotemplate"
+)
+
+type catalogFlags []string
+
+func (c *catalogFlags) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *catalogFlags) Set(v string) error {
+	if !strings.Contains(v, "=") {
+		return fmt.Errorf("expected locale=path")
+	}
+	*c = append(*c, v)
+	return nil
+}
+
+func main() {
+	var catalogs catalogFlags
+	flag.Var(&catalogs, "catalog", "locale=path of a JSON, YAML or .po catalog, repeatable")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: i18nkeys [-catalog locale=path]... template...")
+		os.Exit(2)
+	}
+	used := map[string]bool{}
+	for _, path := range flag.Args() {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			fail(err)
+		}
+		keys, err := gotemplate.ExtractMessageKeys(string(content))
+		if err != nil {
+			fail(fmt.Errorf("%s: %v", path, err))
+		}
+		for _, k := range keys {
+			used[k] = true
+		}
+	}
+	keys := make([]string, 0, len(used))
+	for k := range used {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(catalogs) == 0 {
+		for _, k := range keys {
+			fmt.Println(k)
+		}
+		return
+	}
+	locales := []string{}
+	seen := map[string]bool{}
+	for _, c := range catalogs {
+		parts := strings.SplitN(c, "=", 2)
+		if err := gotemplate.LoadCatalog(parts[0], parts[1]); err != nil {
+			fail(err)
+		}
+		if !seen[parts[0]] {
+			locales = append(locales, parts[0])
+			seen[parts[0]] = true
+		}
+	}
+	missing := false
+	for _, l := range locales {
+		for _, k := range gotemplate.MissingTranslations(l, keys) {
+			fmt.Printf("%s\t%s\n", l, k)
+			missing = true
+		}
+	}
+	if missing {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,351 @@
+package gotemplate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// listItems returns the elements of any slice. A single map (like a lone
+// element decoded by xml_decode) is a list of one, nil is an empty list.
+func listItems(fname string, v interface{}) ([]interface{}, error) {
+	if v == nil {
+		return []interface{}{}, nil
+	}
+	if a, ok := toSlice(v); ok {
+		return a, nil
+	}
+	if _, ok := toMap(v); ok {
+		return []interface{}{v}, nil
+	}
+	return nil, fmt.Errorf("%s: %T is not a list", fname, v)
+}
+
+// sortBy sorts a list by one or more keys, each optionally followed by
+// "desc" or "asc": sortBy .items "price desc" "name". Without keys the
+// elements themselves are compared. Numbers and numeric strings compare
+// numerically, everything else as strings.
+func sortBy(list interface{}, keys ...string) ([]interface{}, error) {
+	items, err := listItems("sortBy", list)
+	if err != nil {
+		return nil, err
+	}
+	type sortKey struct {
+		key  string
+		desc bool
+	}
+	sortKeys := []sortKey{}
+	for _, k := range keys {
+		parts := strings.Fields(k)
+		switch {
+		case len(parts) == 1:
+			sortKeys = append(sortKeys, sortKey{key: parts[0]})
+		case len(parts) == 2 && (strings.EqualFold(parts[1], "desc") || strings.EqualFold(parts[1], "asc")):
+			sortKeys = append(sortKeys, sortKey{key: parts[0], desc: strings.EqualFold(parts[1], "desc")})
+		default:
+			return nil, fmt.Errorf("sortBy: invalid sort key '%s'", k)
+		}
+	}
+	if len(sortKeys) == 0 {
+		sortKeys = append(sortKeys, sortKey{})
+	}
+	sorted := append([]interface{}{}, items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, sk := range sortKeys {
+			a, _ := lookupKey(sorted[i], sk.key)
+			b, _ := lookupKey(sorted[j], sk.key)
+			c := compareValues(a, b)
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != sk.desc
+		}
+		return false
+	})
+	return sorted, nil
+}
+
+// groupBy groups list elements by the string form of their key value
+func groupBy(list interface{}, key string) (map[string][]interface{}, error) {
+	items, err := listItems("groupBy", list)
+	if err != nil {
+		return nil, err
+	}
+	groups := map[string][]interface{}{}
+	for _, item := range items {
+		v, ok := lookupKey(item, key)
+		k := ""
+		if ok && v != nil {
+			k = fmt.Sprint(v)
+		}
+		groups[k] = append(groups[k], item)
+	}
+	return groups, nil
+}
+
+// where keeps the elements whose key matches: where .items "sku" "A1",
+// where .items "qty" ">" 2, where .items "iso" "in" (mkSlice "GB" "IE").
+// Operators are the ones of filter: = != < > <= >= ~= in
+func where(list interface{}, key string, args ...interface{}) ([]interface{}, error) {
+	items, err := listItems("where", list)
+	if err != nil {
+		return nil, err
+	}
+	var c *condition
+	switch len(args) {
+	case 1:
+		c, err = newCondition(key, "=", args[0])
+	case 2:
+		c, err = newCondition(key, fmt.Sprint(args[0]), args[1])
+	default:
+		err = fmt.Errorf("expected value or operator and value")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("where: %v", err)
+	}
+	res := []interface{}{}
+	for _, item := range items {
+		ok, err := c.match(item)
+		if err != nil {
+			return nil, fmt.Errorf("where: %v", err)
+		}
+		if ok {
+			res = append(res, item)
+		}
+	}
+	return res, nil
+}
+
+// pluck returns the key value of each element having the key
+func pluck(list interface{}, key string) ([]interface{}, error) {
+	items, err := listItems("pluck", list)
+	if err != nil {
+		return nil, err
+	}
+	res := []interface{}{}
+	for _, item := range items {
+		if v, ok := lookupKey(item, key); ok {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+// keys returns the sorted keys of a map
+func keys(m interface{}) ([]string, error) {
+	mm, ok := toMap(m)
+	if !ok {
+		return nil, fmt.Errorf("keys: %T is not a map", m)
+	}
+	ks := make([]string, 0, len(mm))
+	for k := range mm {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks, nil
+}
+
+// values returns the values of a map in the order of its sorted keys
+func values(m interface{}) ([]interface{}, error) {
+	ks, err := keys(m)
+	if err != nil {
+		return nil, fmt.Errorf("values: %T is not a map", m)
+	}
+	mm, _ := toMap(m)
+	vs := make([]interface{}, 0, len(ks))
+	for _, k := range ks {
+		vs = append(vs, mm[k])
+	}
+	return vs, nil
+}
+
+// first returns the first element of a list, nil for an empty list
+func first(list interface{}) (interface{}, error) {
+	items, err := listItems("first", list)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// rest returns all but the first element of a list
+func rest(list interface{}) ([]interface{}, error) {
+	items, err := listItems("rest", list)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return []interface{}{}, nil
+	}
+	return items[1:], nil
+}
+
+// reverse returns the list elements in reverse order
+func reverse(list interface{}) ([]interface{}, error) {
+	items, err := listItems("reverse", list)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]interface{}, len(items))
+	for i, item := range items {
+		res[len(items)-1-i] = item
+	}
+	return res, nil
+}
+
+// chunk splits a list into lists of size elements, the last one may be shorter
+func chunk(list interface{}, size int) ([]interface{}, error) {
+	items, err := listItems("chunk", list)
+	if err != nil {
+		return nil, err
+	}
+	if size < 1 {
+		return nil, fmt.Errorf("chunk: invalid size %d", size)
+	}
+	res := []interface{}{}
+	for i := 0; i < len(items); i += size {
+		end := i + size
+		if end > len(items) {
+			end = len(items)
+		}
+		res = append(res, items[i:end])
+	}
+	return res, nil
+}
+
+// flatten returns the elements of nested lists as one list
+func flatten(list interface{}) ([]interface{}, error) {
+	items, err := listItems("flatten", list)
+	if err != nil {
+		return nil, err
+	}
+	res := []interface{}{}
+	for _, item := range items {
+		if _, ok := toSlice(item); ok {
+			sub, _ := flatten(item)
+			res = append(res, sub...)
+			continue
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+// collectionValues returns the list elements, or their key values when key is given
+func collectionValues(fname string, list interface{}, key []string) ([]interface{}, error) {
+	if len(key) > 1 {
+		return nil, fmt.Errorf("%s: too many keys", fname)
+	}
+	if len(key) == 1 {
+		return pluck(list, key[0])
+	}
+	return listItems(fname, list)
+}
+
+// collectionNumbers returns the list elements, or their key values, as numbers
+func collectionNumbers(fname string, list interface{}, key []string) ([]float64, error) {
+	vals, err := collectionValues(fname, list, key)
+	if err != nil {
+		return nil, err
+	}
+	nums := make([]float64, 0, len(vals))
+	for _, v := range vals {
+		f, ok := toNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("%s: %#v is not a number", fname, v)
+		}
+		nums = append(nums, f)
+	}
+	return nums, nil
+}
+
+// sum adds up the list elements, or their key values: sum .items "qty"
+func sum(list interface{}, key ...string) (float64, error) {
+	nums, err := collectionNumbers("sum", list, key)
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, n := range nums {
+		total += n
+	}
+	return total, nil
+}
+
+// avg returns the mean of the list elements, or their key values, 0 for an empty list
+func avg(list interface{}, key ...string) (float64, error) {
+	nums, err := collectionNumbers("avg", list, key)
+	if err != nil || len(nums) == 0 {
+		return 0, err
+	}
+	total := 0.0
+	for _, n := range nums {
+		total += n
+	}
+	return total / float64(len(nums)), nil
+}
+
+// minOf returns the smallest list element, or key value, keeping its type:
+// min .items "price". Called with numbers it returns the smallest: min 3 1 2
+func minOf(args ...interface{}) (interface{}, error) {
+	return extreme("min", args, -1)
+}
+
+// maxOf returns the largest list element, or key value, keeping its type:
+// max .items "price". Called with numbers it returns the largest: max 3 1 2
+func maxOf(args ...interface{}) (interface{}, error) {
+	return extreme("max", args, 1)
+}
+
+func extreme(fname string, args []interface{}, sign int) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s: needs arguments", fname)
+	}
+	var vals []interface{}
+	var err error
+	_, isList := toSlice(args[0])
+	if _, isMap := toMap(args[0]); isList || isMap || args[0] == nil {
+		key := []string{}
+		for _, k := range args[1:] {
+			s, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: key must be a string, not %T", fname, k)
+			}
+			key = append(key, s)
+		}
+		if vals, err = collectionValues(fname, args[0], key); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, a := range args {
+			if !isDecimal(a) {
+				if a, err = numericArg(fname, a); err != nil {
+					return nil, err
+				}
+				if _, ok := toNumber(a); !ok {
+					return nil, fmt.Errorf("%s: unknown type for %v (%T)", fname, a, a)
+				}
+			}
+			vals = append(vals, a)
+		}
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	res := vals[0]
+	for _, v := range vals[1:] {
+		if compareNumbers(v, res)*sign > 0 {
+			res = v
+		}
+	}
+	return res, nil
+}
+
+// count returns the number of list elements
+func count(list interface{}) (int, error) {
+	items, err := listItems("count", list)
+	if err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
@@ -0,0 +1,80 @@
+package gotemplate
+
+import (
+	"testing"
+
+	"github.com/shoobyban/mxj"
+)
+
+var testCollectionItems = map[string]interface{}{
+	"items": []interface{}{
+		map[string]interface{}{"sku": "A", "cat": "tools", "qty": 2, "price": "9.50"},
+		map[string]interface{}{"sku": "B", "cat": "garden", "qty": 1, "price": "120.00"},
+		map[string]interface{}{"sku": "C", "cat": "tools", "qty": 3, "price": "9.50"},
+	},
+	"rows": []map[string]string{
+		{"code": "GB", "name": "Great Britain"},
+		{"code": "DE", "name": "Germany"},
+	},
+	"m": mxj.Map{"b": 2, "a": 1, "c": 3},
+}
+
+func TestCollection(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"sortBy": {
+			Template: `{{ range sortBy .items "price desc" "qty" }}{{ .sku }}{{ end }}|{{ range sortBy .rows "name" }}{{ .code }}{{ end }}`,
+			Values:   testCollectionItems,
+			Result:   "BAC|DEGB",
+		},
+		"sortBy values": {
+			Template: `{{ sortBy (mkSlice 10 "9" 2.5) }}`,
+			Result:   "[2.5 9 10]",
+		},
+		"groupBy": {
+			Template: `{{ range $k, $v := groupBy .items "cat" }}{{ $k }}={{ len $v }};{{ end }}`,
+			Values:   testCollectionItems,
+			Result:   "garden=1;tools=2;",
+		},
+		"where": {
+			Template: `{{ pluck (where .items "qty" ">=" 2) "sku" }}|{{ pluck (where .items "cat" "garden") "sku" }}|{{ pluck (where .rows "code" "in" (mkSlice "DE" "FR")) "name" }}`,
+			Values:   testCollectionItems,
+			Result:   "[A C]|[B]|[Germany]",
+		},
+		"keys values": {
+			Template: `{{ keys .m }}|{{ values .m }}`,
+			Values:   testCollectionItems,
+			Result:   "[a b c]|[1 2 3]",
+		},
+		"first rest reverse": {
+			Template: `{{ (first .items).sku }}|{{ len (rest .items) }}|{{ pluck (reverse .items) "sku" }}|{{ first (mkSlice) }}`,
+			Values:   testCollectionItems,
+			Result:   "A|2|[C B A]|",
+		},
+		"chunk flatten": {
+			Template: `{{ chunk (mkSlice 1 2 3 4 5) 2 }}|{{ flatten (mkSlice 1 (mkSlice 2 (mkSlice 3)) 4) }}`,
+			Result:   "[[1 2] [3 4] [5]]|[1 2 3 4]",
+		},
+		"aggregates": {
+			Template: `{{ sum .items "qty" }}|{{ sum .items "price" }}|{{ avg .items "qty" }}|{{ min .items "price" }}|{{ max .items "qty" }}|{{ count .rows }}`,
+			Values:   testCollectionItems,
+			Result:   "6|139|2|9.50|3|2",
+		},
+		"aggregates single element": {
+			Template: `{{ sum .item "qty" }}|{{ count .item }}|{{ min .item "price" }}|{{ max .item "qty" }}`,
+			Values:   map[string]interface{}{"item": map[string]interface{}{"qty": 2, "price": "9.50"}},
+			Result:   "2|1|9.50|2",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := sum(mkSlice(1, "x")); err == nil {
+		t.Errorf("sum: expected error for non-numeric value")
+	}
+}
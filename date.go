@@ -1,27 +1,210 @@
 package gotemplate
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"time"
 
-func dateFmt(format, datestring string) string {
+	// embedded zone database, so zones load in containers without tzdata
+	_ "time/tzdata"
+)
+
+// location is the engine's zone, nil until SetTimezone or SetLocation
+var location *time.Location
+
+// SetTimezone sets the engine's default IANA zone, e.g. "Europe/London",
+// used for the current time and for parsing dates without an offset.
+// Until it is set dates are parsed as UTC and the current time is in the
+// server's local zone.
+func SetTimezone(zone string) error {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return err
+	}
+	SetLocation(loc)
+	return nil
+}
+
+// SetLocation sets the engine's default location, see SetTimezone, nil
+// restores the defaults
+func SetLocation(loc *time.Location) {
+	flock.Lock()
+	defer flock.Unlock()
+	location = loc
+}
+
+// getLocation is the location dates are parsed and converted in
+func getLocation() *time.Location {
+	flock.Lock()
+	defer flock.Unlock()
+	if location == nil {
+		return time.UTC
+	}
+	return location
+}
+
+// now is the current time of the registered clock in the default location
+func now() time.Time {
+	t := getClock().Now()
+	flock.Lock()
+	defer flock.Unlock()
+	if location == nil {
+		return t
+	}
+	return t.In(location)
+}
+
+// loadZone loads an IANA zone, "" is the default location
+func loadZone(zone string) (*time.Location, error) {
+	if zone == "" {
+		return getLocation(), nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown timezone %s", zone)
+	}
+	return loc, nil
+}
+
+// dateFmt formats a date string, time or epoch number (seconds, or
+// milliseconds when it has more than 11 digits), unparseable dates are
+// returned as they are: .created | date "02/01/06"
+func dateFmt(format string, date interface{}) (string, error) {
 	if format == "ukshort" {
 		format = "02/01/06"
 	}
-	layout := "2006-01-02 15:04:05"
-	t, err := time.Parse(layout, datestring)
+	t, isNumber, err := epochTime(date)
 	if err != nil {
-		t, err = time.Parse("2006-01-02T15:04:05-0700", datestring)
-		if err != nil {
-			return datestring
+		return "", fmt.Errorf("date: %v", err)
+	}
+	if !isNumber {
+		if t, err = toTime(date); err != nil {
+			return fmt.Sprint(date), nil
 		}
 	}
-	return t.Format(format)
+	return t.Format(format), nil
+}
+
+// epochTime converts numbers to time as epoch seconds or milliseconds,
+// isNumber is false for other values
+func epochTime(v interface{}) (t time.Time, isNumber bool, err error) {
+	if i, ok := toInt64(v); ok {
+		if i > 99999999999 || i < -99999999999 {
+			t, err = epochToTime(i, time.Millisecond)
+		} else {
+			t, err = epochToTime(i, time.Second)
+		}
+		return t.In(getLocation()), true, err
+	}
+	switch f := v.(type) {
+	case float32, float64:
+		n, _ := toNumber(f)
+		if math.Abs(n) > 99999999999 {
+			t, err = fromUnixUnit(n, time.Millisecond)
+		} else {
+			t, err = fromUnixUnit(n, time.Second)
+		}
+		return t, true, err
+	}
+	return time.Time{}, false, nil
+}
+
+// fromUnixUnit converts n units since the Unix epoch with a fraction to
+// time in the default location
+func fromUnixUnit(n float64, unit time.Duration) (time.Time, error) {
+	secs := n * float64(unit) / float64(time.Second)
+	if math.IsNaN(secs) || secs < minEpochSeconds || secs >= maxEpochSeconds+1 {
+		return time.Time{}, fmt.Errorf("epoch %v out of range", n)
+	}
+	whole, frac := math.Modf(n)
+	t, err := epochToTime(int64(whole), unit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(time.Duration(math.Round(frac * float64(unit)))).In(getLocation()), nil
+}
+
+// fromUnix converts epoch seconds to time: (fromUnix .ts).Format "2006-01-02"
+func fromUnix(v interface{}) (time.Time, error) {
+	return fromEpoch("fromUnix", v, time.Second)
+}
+
+// fromUnixMilli converts epoch milliseconds to time
+func fromUnixMilli(v interface{}) (time.Time, error) {
+	return fromEpoch("fromUnixMilli", v, time.Millisecond)
+}
+
+// fromUnixMicro converts epoch microseconds to time
+func fromUnixMicro(v interface{}) (time.Time, error) {
+	return fromEpoch("fromUnixMicro", v, time.Microsecond)
+}
+
+func fromEpoch(fname string, v interface{}, unit time.Duration) (time.Time, error) {
+	v, err := numericArg(fname, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var t time.Time
+	if i, ok := toInt64(v); ok {
+		t, err = epochToTime(i, unit)
+		t = t.In(getLocation())
+	} else if f, ok := toNumber(v); ok {
+		t, err = fromUnixUnit(f, unit)
+	} else {
+		return time.Time{}, fmt.Errorf("%s: can't convert %T to time", fname, v)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %v", fname, err)
+	}
+	return t, nil
+}
+
+// inTZ converts a time or date string to the zone: (inTZ "Europe/London" .created).Format "15:04"
+func inTZ(zone string, t interface{}) (time.Time, error) {
+	loc, err := loadZone(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	tt, err := toTime(t)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("inTZ: %v", err)
+	}
+	return tt.In(loc), nil
+}
+
+// dateTZ is date in the zone: dates without offset are read as local time
+// there, dates with offset are converted to it.
+// "2017-03-31 19:59:11" | dateTZ "02/01/06 15:04 MST" "Europe/London"
+func dateTZ(format, zone, datestring string) (string, error) {
+	return convertTZ(format, zone, zone, datestring)
+}
+
+// convertTZ reads a date as local time of zone from and formats it in zone to:
+// convertTZ "15:04" "UTC" "Europe/Paris" "2017-03-31 19:59:11" => 21:59
+func convertTZ(format, from, to, datestring string) (string, error) {
+	if format == "ukshort" {
+		format = "02/01/06"
+	}
+	fromLoc, err := loadZone(from)
+	if err != nil {
+		return "", err
+	}
+	toLoc, err := loadZone(to)
+	if err != nil {
+		return "", err
+	}
+	t, err := parseDateIn(datestring, fromLoc)
+	if err != nil {
+		return "", err
+	}
+	return t.In(toLoc).Format(format), nil
 }
 
 func dateFmtLayout(format, datestring, layout string) string {
 	if format == "ukshort" {
 		format = "02/01/06"
 	}
-	t, err := time.Parse(layout, datestring)
+	t, err := time.ParseInLocation(layout, datestring, getLocation())
 	if err != nil {
 		return err.Error()
 	}
@@ -29,37 +212,47 @@ func dateFmtLayout(format, datestring, layout string) string {
 }
 
 func formatUKDate(datestring string) string {
-	return dateFmt("ukshort", datestring)
+	// only epoch numbers can fail
+	res, _ := dateFmt("ukshort", datestring)
+	return res
 }
 
 func datetime() string {
-	return time.Now().Format("2006-01-02 15:04:05")
+	return now().Format("2006-01-02 15:04:05")
 }
 
 func ukdate() string {
-	return time.Now().Format("02/01/06")
+	return now().Format("02/01/06")
 }
 
 func ukdatetime() string {
-	return time.Now().Format("02/01/06 15:04:05")
+	return now().Format("02/01/06 15:04:05")
 }
 
 func timeFormat(format string) string {
-	return time.Now().Format(format)
+	return now().Format(format)
 }
 
 func timeFormatMinus(format string, minus float64) string {
-	return time.Now().Add(time.Duration(minus) * -time.Second).Format(format)
+	return now().Add(time.Duration(minus) * -time.Second).Format(format)
+}
+
+func unixtimestamp() int64 {
+	return now().Unix()
+}
+
+func unixmilli() int64 {
+	return now().UnixMilli()
 }
 
-func unixtimestamp() int32 {
-	return int32(time.Now().Unix())
+func unixmicro() int64 {
+	return now().UnixMicro()
 }
 
 func nanotimestamp() int64 {
-	return int64(time.Now().UnixNano())
+	return int64(now().UnixNano())
 }
 
 func timestamp() string {
-	return time.Now().String()
+	return now().String()
 }
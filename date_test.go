@@ -1,6 +1,7 @@
 package gotemplate
 
 import (
+	"fmt"
 	"testing"
 	"time"
 )
@@ -12,6 +13,9 @@ type testDateStruct struct {
 }
 
 func TestDate(t *testing.T) {
+	fixed := time.Date(2017, 3, 31, 19, 59, 11, 0, time.Local)
+	RegisterClock(NewFakeClock(fixed, 0))
+	defer RegisterClock(nil)
 	tests := map[string]testDateStruct{
 		"ukdate": {
 			Template: `Date: '{{ "2006-01-02 15:04:05" | date "ukshort" }}'`,
@@ -19,11 +23,15 @@ func TestDate(t *testing.T) {
 		},
 		"timeformatminus": {
 			Template: `{{timeformatminus "02/01/06 15:04:05" 5 }}`,
-			Result:   time.Now().Add(time.Second * -5).Format("02/01/06 15:04:05"),
+			Result:   "31/03/17 19:59:06",
 		},
 		"timeformat": {
 			Template: `{{timeformat "020106"}}`,
-			Result:   time.Now().Format("020106"),
+			Result:   "310317",
+		},
+		"now": {
+			Template: `{{ datetime }}|{{ ukdatetime }}|{{ (now).Format "2006" }}|{{ unixtimestamp }}`,
+			Result:   "2017-03-31 19:59:11|31/03/17 19:59:11|2017|" + fmt.Sprint(fixed.Unix()),
 		},
 	}
 	for name, test := range tests {
@@ -36,3 +44,135 @@ func TestDate(t *testing.T) {
 		}
 	}
 }
+
+func TestTimezone(t *testing.T) {
+	tests := map[string]testDateStruct{
+		"convertTZ": {
+			Template: `{{ convertTZ "2006-01-02 15:04 MST" "UTC" "Europe/Paris" "2017-03-31 23:59:11" }}|{{ convertTZ "15:04" "America/New_York" "Asia/Tokyo" "2017-01-10 10:00:00" }}`,
+			Result:   "2017-04-01 01:59 CEST|00:00",
+		},
+		"dateTZ": {
+			Template: `{{ "2017-01-10 10:00:00" | dateTZ "15:04 MST" "Europe/London" }}|{{ "2017-07-10T10:00:00+0000" | dateTZ "15:04 MST" "Europe/London" }}`,
+			Result:   "10:00 GMT|11:00 BST",
+		},
+		"inTZ": {
+			Template: `{{ (inTZ "Australia/Sydney" "2017-07-10T10:00:00Z").Format "2006-01-02 15:04" }}|{{ (inTZ "UTC" .t).Format "15:04" }}`,
+			Values:   map[string]interface{}{"t": time.Date(2017, 7, 10, 12, 0, 0, 0, time.FixedZone("CEST", 7200))},
+			Result:   "2017-07-10 20:00|10:00",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := Template(`{{ inTZ "Mars/Olympus" "2017-07-10" }}`, nil); err == nil {
+		t.Errorf("inTZ: expected error for unknown zone")
+	}
+}
+
+func TestSetTimezone(t *testing.T) {
+	defer SetLocation(nil)
+	if err := SetTimezone("Nowhere/Special"); err == nil {
+		t.Errorf("SetTimezone: expected error for unknown zone")
+	}
+	if err := SetTimezone("Asia/Kolkata"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Template(`{{ "2017-03-31T12:00:00Z" | date "15:04" }}|{{ timeformat "MST" }}|{{ convertTZ "15:04" "" "UTC" "2017-03-31 12:00:00" }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "12:00|IST|06:30" {
+		t.Errorf("default timezone: %#v != %#v", res, "12:00|IST|06:30")
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2017, 3, 31, 23, 59, 59, 0, time.UTC)
+	c := NewFakeClock(start, time.Second)
+	RegisterClock(c)
+	defer RegisterClock(nil)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	res, err := Template(`{{ timeformat "15:04:05" }} {{ timeformat "15:04:05" }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "23:59:59 00:00:00" {
+		t.Errorf("advancing clock: %#v", res)
+	}
+	c.Set(start)
+	c.Advance(time.Hour)
+	if res, _ := Template(`{{ ukdate }}`, nil); res != "01/04/17" {
+		t.Errorf("advanced clock: %#v", res)
+	}
+}
+
+func TestEpoch(t *testing.T) {
+	RegisterClock(NewFakeClock(time.Date(2040, 1, 2, 3, 4, 5, 6000, time.UTC), 0))
+	defer RegisterClock(nil)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	tests := map[string]testDateStruct{
+		"unix": {
+			Template: `{{ unixtimestamp }}|{{ unixmilli }}|{{ unixmicro }}`,
+			Result:   "2209086245|2209086245000|2209086245000006",
+		},
+		"fromUnix": {
+			Template: `{{ (fromUnix 1490990351).Format "2006-01-02 15:04:05" }}|{{ (fromUnix "1490990351.5").Format "05.0" }}|{{ (fromUnixMilli .ms).Format "15:04:05.000" }}|{{ (fromUnixMicro 1490990351000001).Format "05.000000" }}`,
+			Values:   map[string]interface{}{"ms": float64(1490990351250)},
+			Result:   "2017-03-31 19:59:11|11.5|19:59:11.250|11.000001",
+		},
+		"date inputs": {
+			Template: `{{ .t | date "02/01/06" }}|{{ 1490990351 | date "15:04" }}|{{ .ms | date "15:04" }}|{{ "1490990351" | date "15:04" }}|{{ "nope" | date "15:04" }}`,
+			Values:   map[string]interface{}{"t": time.Date(2017, 3, 31, 0, 0, 0, 0, time.UTC), "ms": int64(1490990351000)},
+			Result:   "31/03/17|19:59|19:59|1490990351|nope",
+		},
+		"after 2262": {
+			Template: `{{ (fromUnix 10000000000).Format "2006-01-02" }}|{{ 10000000000 | date "2006-01-02" }}|{{ (fromUnixMilli 20000000000000).Format "2006-01-02" }}|{{ (fromUnixMicro 9000000000000000).Format "2006" }}|{{ (fromUnix 253402300799.5).Format "2006-01-02 15:04:05.0" }}`,
+			Result:   "2286-11-20|2286-11-20|2603-10-11|2255|9999-12-31 23:59:59.5",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+}
+
+func TestEpochOutOfRange(t *testing.T) {
+	for _, tmpl := range []string{
+		`{{ fromUnix 253402300800 }}`,
+		`{{ fromUnixMilli 9223372036854775807 }}`,
+		`{{ fromUnix -62135596801 }}`,
+		`{{ fromUnix 1e300 }}`,
+		`{{ 99999999999999999 | date "2006" }}`,
+	} {
+		if _, err := Template(tmpl, nil); err == nil {
+			t.Errorf("%s: expected an error", tmpl)
+		}
+	}
+}
+
+func TestDefaultTimezone(t *testing.T) {
+	// without SetTimezone dates parse as UTC, whatever the server zone
+	local := time.Local
+	time.Local, _ = time.LoadLocation("America/New_York")
+	defer func() { time.Local = local }()
+	res, err := Template(`{{ "2017-03-31 19:59:11" | date "2006-01-02T15:04:05Z07:00 MST" }}|{{ dateFrom "15:04 MST" "31/03/2017 19:59" "02/01/2006 15:04" }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "2017-03-31T19:59:11Z UTC|19:59 UTC" {
+		t.Errorf("%s != 2017-03-31T19:59:11Z UTC|19:59 UTC", res)
+	}
+}
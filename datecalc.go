@@ -0,0 +1,337 @@
+package gotemplate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var holidays = map[string]bool{}
+
+// SetHolidays replaces the holiday calendar used by the business day funcs
+func SetHolidays(days ...time.Time) {
+	h := map[string]bool{}
+	for _, d := range days {
+		h[d.Format("2006-01-02")] = true
+	}
+	flock.Lock()
+	defer flock.Unlock()
+	holidays = h
+}
+
+// LoadHolidays replaces the holiday calendar from a file on the registered
+// filesystem (see RegisterFS). The file is either a JSON list of dates or of
+// objects with a "date" key, or text with a date at the start of each line,
+// the rest of the line and lines starting with # are ignored:
+//
+//	2017-12-25 Christmas Day
+//	26/12/2017 Boxing Day
+func LoadHolidays(path string) error {
+	content, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	dates, err := holidayDates(content)
+	if err != nil {
+		return fmt.Errorf("LoadHolidays %s: %v", path, err)
+	}
+	days := []time.Time{}
+	for _, s := range dates {
+		d, err := ParseDate(s)
+		if err != nil {
+			return fmt.Errorf("LoadHolidays %s: %v", path, err)
+		}
+		days = append(days, d)
+	}
+	SetHolidays(days...)
+	return nil
+}
+
+func holidayDates(content []byte) ([]string, error) {
+	dates := []string{}
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		var list []interface{}
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			switch v := item.(type) {
+			case string:
+				dates = append(dates, v)
+			case map[string]interface{}:
+				if d, ok := v["date"].(string); ok {
+					dates = append(dates, d)
+				}
+			}
+		}
+		return dates, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		dates = append(dates, fields[0])
+	}
+	return dates, scanner.Err()
+}
+
+// isBusinessDay reports whether t is a weekday and not a holiday
+func isBusinessDay(v interface{}) (bool, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return false, err
+	}
+	return businessDay(t), nil
+}
+
+func businessDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	flock.Lock()
+	defer flock.Unlock()
+	return !holidays[t.Format("2006-01-02")]
+}
+
+// dateUnit normalises unit names: "days", "day" and "d" are all "day"
+func dateUnit(unit string) (string, error) {
+	u := strings.ToLower(strings.TrimSpace(unit))
+	if u != "s" {
+		u = strings.TrimSuffix(u, "s")
+	}
+	switch u {
+	case "second", "sec", "s":
+		return "second", nil
+	case "minute", "min":
+		return "minute", nil
+	case "hour", "h":
+		return "hour", nil
+	case "day", "d":
+		return "day", nil
+	case "week", "w":
+		return "week", nil
+	case "month":
+		return "month", nil
+	case "year", "y":
+		return "year", nil
+	case "businessday", "business day", "bd":
+		return "businessday", nil
+	}
+	return "", fmt.Errorf("Unknown date unit %s", unit)
+}
+
+// addBusinessDays moves n business days, skipping weekends and holidays.
+// Whole weeks are jumped at once, only the rest is walked day by day.
+func addBusinessDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		if weeks := n / 5; weeks > 0 {
+			next := t.AddDate(0, 0, 7*weeks*step)
+			if step > 0 {
+				n -= businessDaysIn(t.AddDate(0, 0, 1), next)
+			} else {
+				n -= businessDaysIn(next, t.AddDate(0, 0, -1))
+			}
+			t = next
+			continue
+		}
+		t = t.AddDate(0, 0, step)
+		if businessDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// businessDaysIn counts the business days from the date of first to the
+// date of last, both included: 5 a week, then the rest of the days and the
+// holidays in the range
+func businessDaysIn(first, last time.Time) int {
+	days := int(calendarDiff(first, last, 0, 1)) + 1
+	if days <= 0 {
+		return 0
+	}
+	n := days / 7 * 5
+	for i := 0; i < days%7; i++ {
+		if wd := (int(first.Weekday()) + i) % 7; wd != int(time.Saturday) && wd != int(time.Sunday) {
+			n++
+		}
+	}
+	from, to := first.Format("2006-01-02"), last.Format("2006-01-02")
+	flock.Lock()
+	defer flock.Unlock()
+	for day := range holidays {
+		if day < from || day > to {
+			continue
+		}
+		if d, err := time.Parse("2006-01-02", day); err == nil && d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			n--
+		}
+	}
+	return n
+}
+
+// addMonths adds months keeping the day, clamped to the last day of the
+// target month: Jan 31 + 1 month is Feb 28, not Mar 3
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// dateAdd adds n units to a time or date string, negative n subtracts:
+// .ordered | dateAdd 3 "businessdays"
+func dateAdd(n interface{}, unit string, v interface{}) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	u, err := dateUnit(unit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	i, err := intArg("dateAdd", n)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch u {
+	case "second":
+		return t.Add(time.Duration(i) * time.Second), nil
+	case "minute":
+		return t.Add(time.Duration(i) * time.Minute), nil
+	case "hour":
+		return t.Add(time.Duration(i) * time.Hour), nil
+	case "day":
+		return t.AddDate(0, 0, i), nil
+	case "week":
+		return t.AddDate(0, 0, 7*i), nil
+	case "month":
+		return addMonths(t, i), nil
+	case "year":
+		return addMonths(t, 12*i), nil
+	}
+	return addBusinessDays(t, i), nil
+}
+
+// dateDiff counts the complete units from from to to, negative when to is
+// earlier: dateDiff "days" .ordered .delivered
+func dateDiff(unit string, from, to interface{}) (int64, error) {
+	a, err := toTime(from)
+	if err != nil {
+		return 0, err
+	}
+	b, err := toTime(to)
+	if err != nil {
+		return 0, err
+	}
+	u, err := dateUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	b = b.In(a.Location())
+	switch u {
+	case "second":
+		return int64(b.Sub(a) / time.Second), nil
+	case "minute":
+		return int64(b.Sub(a) / time.Minute), nil
+	case "hour":
+		return int64(b.Sub(a) / time.Hour), nil
+	case "day":
+		return calendarDiff(a, b, 0, 1), nil
+	case "week":
+		return calendarDiff(a, b, 0, 1) / 7, nil
+	case "month":
+		return calendarDiff(a, b, 1, 0), nil
+	case "year":
+		return calendarDiff(a, b, 1, 0) / 12, nil
+	}
+	days := int(calendarDiff(a, b, 0, 1))
+	if days < 0 {
+		return -int64(businessDaysIn(a.AddDate(0, 0, days), a.AddDate(0, 0, -1))), nil
+	}
+	return int64(businessDaysIn(a.AddDate(0, 0, 1), a.AddDate(0, 0, days))), nil
+}
+
+// calendarDiff counts whole months or days between a and b, so that daylight
+// saving changes and month lengths don't matter, months being added like
+// dateAdd does
+func calendarDiff(a, b time.Time, months, days int) int64 {
+	var n int
+	if months > 0 {
+		n = (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+	} else {
+		ad := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+		bd := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+		n = int(bd.Sub(ad) / (24 * time.Hour))
+	}
+	if n > 0 && addMonths(a, n*months).AddDate(0, 0, n*days).After(b) {
+		n--
+	}
+	if n < 0 && addMonths(a, n*months).AddDate(0, 0, n*days).Before(b) {
+		n++
+	}
+	return int64(n)
+}
+
+// startOf truncates to the start of the hour, day, week (Monday), month or
+// year: startOf "month" .date
+func startOf(unit string, v interface{}) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	u, err := dateUnit(unit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := t.Date()
+	switch u {
+	case "hour":
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location()), nil
+	case "day":
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location()), nil
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location()), nil
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location()), nil
+	case "year":
+		return time.Date(y, 1, 1, 0, 0, 0, 0, t.Location()), nil
+	}
+	return time.Time{}, fmt.Errorf("startOf: unsupported unit %s", unit)
+}
+
+// endOf is the last nanosecond of the hour, day, week, month or year:
+// endOf "month" .date
+func endOf(unit string, v interface{}) (time.Time, error) {
+	start, err := startOf(unit, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("endOf: %v", strings.TrimPrefix(err.Error(), "startOf: "))
+	}
+	next, err := dateAdd(1, unit, start)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return next.Add(-time.Nanosecond), nil
+}
+
+// isoWeek is the ISO 8601 week number: isoWeek "2017-01-01" => 52
+func isoWeek(v interface{}) (int, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return 0, err
+	}
+	_, w := t.ISOWeek()
+	return w, nil
+}
@@ -0,0 +1,104 @@
+package gotemplate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestDateCalc(t *testing.T) {
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	tests := map[string]testTemplateStruct{
+		"dateAdd": {
+			Template: `{{ (dateAdd 3 "days" "2017-03-30").Format "02/01" }}|{{ ("2017-01-31" | dateAdd 1 "month").Format "02/01" }}|{{ (dateAdd -2 "h" "2017-03-30 01:00:00").Format "02/01 15:04" }}|{{ (dateAdd "1" "year" "2016-02-29").Format "2006-01-02" }}|{{ (dateAdd -1 "month" "2017-03-31").Format "02/01" }}|{{ (dateAdd 13 "months" "2016-01-31 10:30:00").Format "2006-01-02 15:04" }}`,
+			Result:   "02/04|28/02|29/03 23:00|2017-02-28|28/02|2017-02-28 10:30",
+		},
+		"dateDiff": {
+			Template: `{{ dateDiff "days" "2017-03-30 12:00:00" "2017-04-02 11:00:00" }}|{{ dateDiff "months" "2017-01-31" "2017-03-30" }}|{{ dateDiff "years" "2000-06-01" "2017-05-31" }}|{{ dateDiff "hours" "2017-04-02" "2017-03-30" }}|{{ dateDiff "weeks" "2017-03-01" "2017-03-31" }}|{{ dateDiff "months" "2017-01-31" "2017-02-28" }}`,
+			Result:   "2|1|16|-72|4|1",
+		},
+		"startOf endOf": {
+			Template: `{{ (startOf "week" "2017-04-02 10:00:00").Format "Mon 02/01 15:04" }}|{{ (endOf "month" "2017-02-10").Format "02/01 15:04:05" }}|{{ (startOf "year" "2017-02-10").Format "2006-01-02" }}`,
+			Result:   "Mon 27/03 00:00|28/02 23:59:59|2017-01-01",
+		},
+		"isoWeek": {
+			Template: `{{ isoWeek "2017-01-01" }}|{{ isoWeek "2017-01-02" }}`,
+			Result:   "52|1",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := Template(`{{ dateAdd 1 "fortnight" "2017-01-01" }}`, nil); err == nil {
+		t.Errorf("dateAdd: expected error for unknown unit")
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	defer SetHolidays()
+	memfs := afero.NewMemMapFs()
+	afero.WriteFile(memfs, "holidays.txt", []byte("# UK bank holidays\n2017-04-14 Good Friday\n17/04/2017 Easter Monday\n"), 0644)
+	afero.WriteFile(memfs, "holidays.json", []byte(`[{"title": "Christmas Day", "date": "2017-12-25"}, "2017-12-26"]`), 0644)
+	RegisterFS(memfs)
+	defer RegisterFS(nil)
+	if err := LoadHolidays("holidays.txt"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Template(`{{ (dateAdd 2 "businessdays" "2017-04-13").Format "Mon 02/01" }}|{{ (dateAdd -1 "bd" "2017-04-18").Format "Mon 02/01" }}|{{ dateDiff "businessdays" "2017-04-10" "2017-04-21" }}|{{ isBusinessDay "2017-04-17" }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Wed 19/04|Thu 13/04|7|false"; res != want {
+		t.Errorf("business days: %#v != %#v", res, want)
+	}
+	if err := LoadHolidays("holidays.json"); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := dateAdd(1, "businessday", "2017-12-22"); d.Day() != 27 {
+		t.Errorf("JSON holidays: %v", d)
+	}
+	if err := LoadHolidays("missing.txt"); err == nil {
+		t.Errorf("LoadHolidays: expected error for missing file")
+	}
+
+	// the week arithmetic matches walking day by day
+	SetHolidays(time.Date(2017, 12, 25, 0, 0, 0, 0, time.UTC), time.Date(2017, 12, 26, 0, 0, 0, 0, time.UTC), time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2017, 12, 30, 0, 0, 0, 0, time.UTC))
+	start := time.Date(2017, 12, 20, 9, 0, 0, 0, time.UTC)
+	for n := -30; n <= 30; n++ {
+		want, left := start, n
+		for left != 0 {
+			if n > 0 {
+				want = want.AddDate(0, 0, 1)
+			} else {
+				want = want.AddDate(0, 0, -1)
+			}
+			if businessDay(want) {
+				if n > 0 {
+					left--
+				} else {
+					left++
+				}
+			}
+		}
+		if got := addBusinessDays(start, n); !got.Equal(want) {
+			t.Errorf("addBusinessDays %d: %v != %v", n, got, want)
+		}
+		if diff, _ := dateDiff("bd", start, want); diff != int64(n) {
+			t.Errorf("dateDiff %v: %d != %d", want, diff, n)
+		}
+	}
+	// 26090 weekdays, the Saturday holiday does not count
+	if diff, _ := dateDiff("bd", "2000-01-03", "2100-01-04"); diff != 26090-3 {
+		t.Errorf("dateDiff over a century: %d", diff)
+	}
+}
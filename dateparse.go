@@ -0,0 +1,181 @@
+package gotemplate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout names for numeric dates, usable in SetDateLayouts next to Go
+// time layouts
+const (
+	LayoutUnix      = "unix"      // epoch seconds: 1490997551
+	LayoutUnixMilli = "unixmilli" // epoch milliseconds: 1490997551000
+	LayoutExcel     = "excel"     // Excel serial days: 42825.5
+)
+
+var defaultDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05-0700",
+	time.RFC3339Nano,
+	time.RFC1123,
+	time.RFC1123Z,
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+	"20060102",
+}
+
+var dateLayouts = defaultDateLayouts
+
+// SetDateLayouts sets the ordered list of layouts parseDate and the date
+// funcs try, the first one that parses wins. Layouts are Go time layouts or
+// LayoutUnix, LayoutUnixMilli and LayoutExcel, which are not in the defaults
+// so numeric strings like "2017" are not taken as dates unless asked for.
+// No layouts restore the defaults.
+func SetDateLayouts(layouts ...string) {
+	flock.Lock()
+	defer flock.Unlock()
+	if len(layouts) == 0 {
+		layouts = defaultDateLayouts
+	}
+	dateLayouts = append([]string{}, layouts...)
+}
+
+func getDateLayouts() []string {
+	flock.Lock()
+	defer flock.Unlock()
+	return dateLayouts
+}
+
+// ParseDate parses s with the date layouts, dates without an offset are
+// taken as local time of the default location
+func ParseDate(s string) (time.Time, error) {
+	return parseDateIn(s, getLocation())
+}
+
+// parseDateIn parses datestring with the date layouts, strings without an
+// offset are taken as local time of loc
+func parseDateIn(datestring string, loc *time.Location) (time.Time, error) {
+	s := strings.TrimSpace(datestring)
+	for _, layout := range getDateLayouts() {
+		var t time.Time
+		var err error
+		switch layout {
+		case LayoutUnix:
+			t, err = parseEpoch(s, 9, 11, time.Second, loc)
+		case LayoutUnixMilli:
+			t, err = parseEpoch(s, 12, 14, time.Millisecond, loc)
+		case LayoutExcel:
+			t, err = parseExcel(s, loc)
+		default:
+			t, err = time.ParseInLocation(layout, s, loc)
+		}
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parseDate: can't parse %q as date", datestring)
+}
+
+// parseEpoch reads an integer with minDigits to maxDigits digits as unit
+// since the Unix epoch, so 20170331 is not mistaken for a timestamp
+func parseEpoch(s string, minDigits, maxDigits int, unit time.Duration, loc *time.Location) (time.Time, error) {
+	digits := strings.TrimPrefix(s, "-")
+	if len(digits) < minDigits || len(digits) > maxDigits {
+		return time.Time{}, fmt.Errorf("not an epoch")
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := epochToTime(n, unit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// epoch seconds of 0001-01-01 and 9999-12-31 23:59:59 UTC, the dates the
+// date funcs can format
+const (
+	minEpochSeconds = -62135596800
+	maxEpochSeconds = 253402300799
+)
+
+// epochToTime converts n units (second, millisecond...) since the Unix
+// epoch to time, dates outside years 1 to 9999 are errors
+func epochToTime(n int64, unit time.Duration) (time.Time, error) {
+	perSecond := int64(time.Second / unit)
+	sec, frac := n/perSecond, n%perSecond
+	if sec < minEpochSeconds || sec > maxEpochSeconds {
+		return time.Time{}, fmt.Errorf("epoch %d out of range", n)
+	}
+	return time.Unix(sec, frac*int64(unit)), nil
+}
+
+// largest Excel serial, 9999-12-31
+const maxExcelSerial = 2958465
+
+// fromExcel converts an Excel serial date (days since 1899-12-30, the
+// fraction being the time of day) to time: (fromExcel .day).Format "02/01/2006"
+func fromExcel(v interface{}) (time.Time, error) {
+	t, err := parseExcel(strings.TrimSpace(fmt.Sprint(v)), getLocation())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fromExcel: %v is not an Excel date", v)
+	}
+	return t, nil
+}
+
+// parseExcel reads Excel serial dates in loc, see fromExcel
+func parseExcel(s string, loc *time.Location) (time.Time, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 1 || f > maxExcelSerial {
+		return time.Time{}, fmt.Errorf("not an Excel date")
+	}
+	if f < 61 {
+		// Excel counts the non-existent 1900-02-29
+		f++
+	}
+	days := math.Floor(f)
+	t := time.Date(1899, 12, 30, 0, 0, 0, 0, loc).AddDate(0, 0, int(days))
+	secs := math.Round((f - days) * 86400)
+	return t.Add(time.Duration(secs) * time.Second), nil
+}
+
+// toTime converts times, date strings and epoch numbers to time
+func toTime(v interface{}) (time.Time, error) {
+	var s string
+	switch d := v.(type) {
+	case time.Time:
+		return d, nil
+	case *time.Time:
+		if d != nil {
+			return *d, nil
+		}
+	case string:
+		s = d
+	default:
+		t, isNumber, err := epochTime(v)
+		if !isNumber {
+			return time.Time{}, fmt.Errorf("can't convert %T to time", v)
+		}
+		return t, err
+	}
+	return ParseDate(s)
+}
+
+// parseDate parses a date string or epoch number to time, failures are
+// errors unless a fallback is given: parseDate .created "n/a"
+func parseDate(v interface{}, fallback ...interface{}) (interface{}, error) {
+	t, err := toTime(v)
+	if err != nil {
+		if len(fallback) > 0 {
+			return fallback[0], nil
+		}
+		return nil, err
+	}
+	return t, nil
+}
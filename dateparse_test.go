@@ -0,0 +1,115 @@
+package gotemplate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	tests := map[string]string{
+		"2017-03-31 19:59:11":             "2017-03-31 19:59:11",
+		"2017-03-31T19:59:11+02:00":       "2017-03-31 17:59:11",
+		"2017-03-31T19:59:11.123Z":        "2017-03-31 19:59:11",
+		"Fri, 31 Mar 2017 19:59:11 GMT":   "2017-03-31 19:59:11",
+		"Fri, 31 Mar 2017 19:59:11 +0100": "2017-03-31 18:59:11",
+		"31/03/2017":                      "2017-03-31 00:00:00",
+		"31/03/2017 19:59:11":             "2017-03-31 19:59:11",
+		"20170331":                        "2017-03-31 00:00:00",
+	}
+	for in, want := range tests {
+		d, err := ParseDate(in)
+		if err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if res := d.UTC().Format("2006-01-02 15:04:05"); res != want {
+			t.Errorf("%s: %#v != %#v", in, res, want)
+		}
+	}
+	for _, in := range []string{"31/13/2017", "20171399", "yesterday", "", "2017", "1490990351", "42825.5"} {
+		if _, err := ParseDate(in); err == nil {
+			t.Errorf("%s: expected error", in)
+		}
+	}
+}
+
+func TestParseDateTemplate(t *testing.T) {
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	tests := map[string]testTemplateStruct{
+		"parseDate": {
+			Template: `{{ (parseDate "31/03/2017").Format "Jan 2" }}|{{ (parseDate .epoch).Format "2006-01-02" }}|{{ parseDate "soon" "n/a" }}`,
+			Values:   map[string]interface{}{"epoch": float64(1490990351)},
+			Result:   "Mar 31|2017-03-31|n/a",
+		},
+		"numbers are not dates": {
+			Template: `{{ "2017" | date "2006-01-02" }}|{{ "42825" | date "2006-01-02" }}`,
+			Result:   "2017|42825",
+		},
+		"fromExcel": {
+			Template: `{{ (fromExcel 42825.5).Format "2006-01-02 15:04" }}|{{ (fromExcel "1").Format "2006-01-02" }}`,
+			Result:   "2017-03-31 12:00|1900-01-01",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := Template(`{{ parseDate "soon" }}`, nil); err == nil {
+		t.Errorf("parseDate: expected error without fallback")
+	}
+	if _, err := Template(`{{ fromExcel 2958466 }}`, nil); err == nil {
+		t.Errorf("fromExcel: expected error after 9999-12-31")
+	}
+}
+
+func TestSetDateLayouts(t *testing.T) {
+	defer SetDateLayouts()
+	SetDateLayouts("01/02/2006", LayoutUnix)
+	d, err := ParseDate("03/31/2017")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Day() != 31 {
+		t.Errorf("US layout: %v", d)
+	}
+	if _, err := ParseDate("2017-03-31"); err == nil {
+		t.Errorf("SetDateLayouts: expected only the given layouts to be tried")
+	}
+}
+
+func TestNumericDateLayouts(t *testing.T) {
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	defer SetDateLayouts()
+	SetDateLayouts(LayoutUnix, LayoutUnixMilli, LayoutExcel)
+	tests := map[string]string{
+		"1490990351":     "2017-03-31 19:59:11",
+		"1490990351000":  "2017-03-31 19:59:11",
+		"-1490990351":    "1922-10-03 04:00:49",
+		"99999999999":    "5138-11-16 09:46:39",
+		"42825.5":        "2017-03-31 12:00:00",
+		"1":              "1900-01-01 00:00:00",
+		"99999999999999": "5138-11-16 09:46:39",
+	}
+	for in, want := range tests {
+		d, err := ParseDate(in)
+		if err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if res := d.Format("2006-01-02 15:04:05"); res != want {
+			t.Errorf("%s: %#v != %#v", in, res, want)
+		}
+	}
+	if _, err := epochToTime(253402300800, time.Second); err == nil {
+		t.Errorf("epochToTime: expected error after 9999-12-31")
+	}
+}
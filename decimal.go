@@ -0,0 +1,127 @@
+package gotemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/shopspring/decimal"
+)
+
+// toDecimal converts decimals, numbers and numeric strings to an exact
+// decimal. Floats are taken by their shortest representation, so 0.1 is 0.1.
+func toDecimal(v interface{}) (decimal.Decimal, error) {
+	switch d := v.(type) {
+	case decimal.Decimal:
+		return d, nil
+	case *decimal.Decimal:
+		if d != nil {
+			return *d, nil
+		}
+	case json.Number:
+		return decimal.NewFromString(string(d))
+	case float32:
+		return decimal.NewFromFloat32(d), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decimal.NewFromInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return decimal.NewFromUint64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return decimal.NewFromFloat(rv.Float()), nil
+	case reflect.String:
+		d, err := decimal.NewFromString(normalizeNumber(rv.String()))
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("dec: can't convert %q to decimal", rv.String())
+		}
+		return d, nil
+	}
+	return decimal.Zero, fmt.Errorf("dec: can't convert %#v (%T) to decimal", v, v)
+}
+
+func isDecimal(v interface{}) bool {
+	switch v.(type) {
+	case decimal.Decimal, *decimal.Decimal:
+		return true
+	}
+	return false
+}
+
+// dec creates an exact decimal from a string or number: dec "0.1" | add (dec "0.2") => 0.3
+func dec(v interface{}) (decimal.Decimal, error) {
+	return toDecimal(v)
+}
+
+// decimalArith is add, subtract, multiply and divide for decimals: a op b
+func decimalArith(op string, a, b interface{}) (interface{}, error) {
+	ad, err := toDecimal(a)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	bd, err := toDecimal(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	switch op {
+	case "add":
+		return ad.Add(bd), nil
+	case "subtract":
+		return ad.Sub(bd), nil
+	case "multiply":
+		return ad.Mul(bd), nil
+	case "divide":
+		if bd.IsZero() {
+			return nil, fmt.Errorf("divide: division by zero")
+		}
+		return ad.Div(bd), nil
+	}
+	return nil, fmt.Errorf("unknown decimal operation %s", op)
+}
+
+// roundDecimal rounds to places decimal digits with one of the modes:
+// half-up (default, halves away from zero), half-even (banker's), down
+// (towards zero), up (away from zero), ceiling and floor
+func roundDecimal(d decimal.Decimal, places int, mode string) (decimal.Decimal, error) {
+	p := int32(places)
+	switch mode {
+	case "", "half-up":
+		return d.Round(p), nil
+	case "half-even":
+		return d.RoundBank(p), nil
+	case "down":
+		return d.RoundDown(p), nil
+	case "up":
+		return d.RoundUp(p), nil
+	case "ceiling", "ceil":
+		return d.RoundCeil(p), nil
+	case "floor":
+		return d.RoundFloor(p), nil
+	}
+	return d, fmt.Errorf("round: unknown rounding mode '%s'", mode)
+}
+
+// round rounds a number to places decimal digits as an exact decimal:
+// round 2 .price "half-even"
+func round(places int, v interface{}, mode ...string) (decimal.Decimal, error) {
+	d, err := toDecimal(v)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("round: %v", err)
+	}
+	m := ""
+	if len(mode) > 0 {
+		m = mode[0]
+	}
+	return roundDecimal(d, places, m)
+}
+
+// toFixed rounds like round and formats with exactly places decimal digits:
+// toFixed 2 "12.3" => 12.30
+func toFixed(places int, v interface{}, mode ...string) (string, error) {
+	d, err := round(places, v, mode...)
+	if err != nil {
+		return "", err
+	}
+	return d.StringFixed(int32(places)), nil
+}
@@ -0,0 +1,43 @@
+package gotemplate
+
+import "testing"
+
+func TestDecimal(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"exact add": {
+			Template: `{{ dec "0.1" | add (dec "0.2") }}|{{ add (dec .a) .b }}`,
+			Values:   map[string]interface{}{"a": 0.1, "b": "0.2"},
+			Result:   "0.3|0.3",
+		},
+		"vat": {
+			Template: `{{ $net := mul (dec .qty) .price }}{{ $vat := mul $net "0.2" | round 2 }}{{ $net }}+{{ $vat }}={{ add $net $vat | toFixed 2 }}`,
+			Values:   map[string]interface{}{"qty": 3, "price": "19.99"},
+			Result:   "59.97+11.99=71.96",
+		},
+		"div": {
+			Template: `{{ div 3 (dec "10") }}|{{ div 3 (dec "10") | toFixed 4 }}`,
+			Result:   "3.3333333333333333|3.3333",
+		},
+		"rounding modes": {
+			Template: `{{ round 2 "2.345" }} {{ round 2 "2.345" "half-even" }} {{ round 2 "2.349" "down" }} {{ round 0 "-2.1" "ceiling" }} {{ round 0 "-2.1" "floor" }} {{ round 1 "2.01" "up" }}`,
+			Result:   "2.35 2.34 2.34 -2 -3 2.1",
+		},
+		"decimal format": {
+			Template: `{{ dec "1.005" | decimal "0,2" }}|{{ decimal "8,3" (dec "2.5") }}`,
+			Result:   "1.01|   2.5",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	zero, _ := dec("0")
+	if _, err := divide(zero, 1); err == nil {
+		t.Errorf("divide by zero decimal: expected error")
+	}
+}
@@ -0,0 +1,323 @@
+package gotemplate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/shoobyban/mxj"
+)
+
+// SetPath sets value at the dotted path ("a.b.0.c") inside data, creating
+// missing maps on the way (or a list when the missing level is indexed by 0).
+// Integer keys index lists, negative ones from the end, and the index just
+// past the end appends. data is modified in place; the returned value is the
+// new root, which differs from data only when data was nil or a list grew.
+func SetPath(data interface{}, path string, value interface{}) (interface{}, error) {
+	return setPathValue(data, strings.Split(path, "."), value)
+}
+
+// DeletePath removes the map key or list element at the dotted path inside
+// data in place and returns the new root. Missing paths are not an error.
+func DeletePath(data interface{}, path string) (interface{}, error) {
+	return deletePathValue(data, strings.Split(path, "."))
+}
+
+// Clone returns a deep copy of maps and slices (keeping their types, like
+// mxj.Map), other values are returned as they are
+func Clone(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(data)).Interface()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, deepCopy(v.MapIndex(k)))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	}
+	return v
+}
+
+func setPathValue(cur interface{}, keys []string, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+	key := keys[0]
+	if cur == nil {
+		if key == "0" {
+			cur = []interface{}{}
+		} else {
+			cur = map[string]interface{}{}
+		}
+	}
+	switch c := cur.(type) {
+	case map[string]interface{}:
+		child, err := setPathValue(c[key], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		c[key] = child
+		return c, nil
+	case mxj.Map:
+		child, err := setPathValue(c[key], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		c[key] = child
+		return c, nil
+	case []interface{}:
+		i, err := listIndex(key, len(c))
+		if key == strconv.Itoa(len(c)) {
+			i, err = len(c), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if i == len(c) {
+			c = append(c, nil)
+		}
+		child, err := setPathValue(c[i], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = child
+		return c, nil
+	}
+	if len(keys) == 1 {
+		rv := reflect.ValueOf(cur)
+		if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+			val := reflect.ValueOf(value)
+			if !val.IsValid() || !val.Type().AssignableTo(rv.Type().Elem()) {
+				return nil, fmt.Errorf("setPath: can't set %T in %T. [Key:%s]", value, cur, key)
+			}
+			rv.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), val)
+			return cur, nil
+		}
+	}
+	return nil, fmt.Errorf("setPath: can't set key in %T. [Key:%s]", cur, key)
+}
+
+func deletePathValue(cur interface{}, keys []string) (interface{}, error) {
+	if len(keys) == 0 || cur == nil {
+		return cur, nil
+	}
+	key := keys[0]
+	if a, ok := cur.([]interface{}); ok {
+		i, err := listIndex(key, len(a))
+		if err != nil {
+			return cur, nil
+		}
+		if len(keys) == 1 {
+			return append(a[:i:i], a[i+1:]...), nil
+		}
+		child, err := deletePathValue(a[i], keys[1:])
+		if err != nil {
+			return nil, err
+		}
+		a[i] = child
+		return a, nil
+	}
+	rv := reflect.ValueOf(cur)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("deletePath: can't delete key in %T. [Key:%s]", cur, key)
+	}
+	k := reflect.ValueOf(key).Convert(rv.Type().Key())
+	if len(keys) == 1 {
+		rv.SetMapIndex(k, reflect.Value{})
+		return cur, nil
+	}
+	child := rv.MapIndex(k)
+	if !child.IsValid() {
+		return cur, nil
+	}
+	c, err := deletePathValue(child.Interface(), keys[1:])
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		rv.SetMapIndex(k, reflect.ValueOf(c))
+	}
+	return cur, nil
+}
+
+// listIndex parses a list index, counting negative indices from the end
+func listIndex(key string, length int) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid list index. [Index:%s]", key)
+	}
+	if i < 0 {
+		i += length
+	}
+	if i < 0 || i >= length {
+		return 0, fmt.Errorf("Index out of bounds. [Index:%s]", key)
+	}
+	return i, nil
+}
+
+// setPath is SetPath on a copy of data: setPath $m "a.b.0.c" "v"
+func setPath(data interface{}, path string, value interface{}) (interface{}, error) {
+	return SetPath(Clone(data), path, value)
+}
+
+// deletePath is DeletePath on a copy of data
+func deletePath(data interface{}, path string) (interface{}, error) {
+	return DeletePath(Clone(data), path)
+}
+
+// deepMerge returns a copy of dst with src merged into it. Nested maps are
+// merged, lists are handled by strategy: "replace" (default), "append" or
+// "union" (append elements not yet present).
+func deepMerge(dst, src interface{}, strategy ...string) (interface{}, error) {
+	s := "replace"
+	if len(strategy) > 0 {
+		s = strategy[0]
+	}
+	if s != "replace" && s != "append" && s != "union" {
+		return nil, fmt.Errorf("deepMerge: unknown list strategy '%s'", s)
+	}
+	if dst == nil {
+		return Clone(src), nil
+	}
+	dm, ok := toMap(dst)
+	if !ok {
+		return nil, fmt.Errorf("deepMerge: %T is not a map", dst)
+	}
+	d := map[string]interface{}{}
+	for k, v := range dm {
+		d[k] = Clone(v)
+	}
+	sm, ok := toMap(src)
+	if !ok && src != nil {
+		return nil, fmt.Errorf("deepMerge: %T is not a map", src)
+	}
+	for k, v := range sm {
+		merged, err := mergeValue(d[k], v, s)
+		if err != nil {
+			return nil, err
+		}
+		d[k] = merged
+	}
+	return mapLike(dst, d), nil
+}
+
+func mergeValue(dst, src interface{}, strategy string) (interface{}, error) {
+	if _, ok := toMap(dst); ok {
+		if _, ok := toMap(src); ok {
+			return deepMerge(dst, src, strategy)
+		}
+	}
+	da, dok := toSlice(dst)
+	sa, sok := toSlice(src)
+	if !dok || !sok || strategy == "replace" {
+		return Clone(src), nil
+	}
+	res := append([]interface{}{}, da...)
+	for _, v := range sa {
+		if strategy == "union" && containsDeep(res, v) {
+			continue
+		}
+		res = append(res, Clone(v))
+	}
+	return res, nil
+}
+
+func containsDeep(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// mapLike keeps mxj.Map results as mxj.Map
+func mapLike(orig interface{}, m map[string]interface{}) interface{} {
+	if _, ok := orig.(mxj.Map); ok {
+		return mxj.Map(m)
+	}
+	return m
+}
+
+// pick returns a copy of m with only the given keys
+func pick(m interface{}, keys ...string) (interface{}, error) {
+	mm, ok := toMap(m)
+	if !ok {
+		return nil, fmt.Errorf("pick: %T is not a map", m)
+	}
+	res := map[string]interface{}{}
+	for _, k := range keys {
+		if v, ok := mm[k]; ok {
+			res[k] = Clone(v)
+		}
+	}
+	return mapLike(m, res), nil
+}
+
+// omit returns a copy of m without the given keys
+func omit(m interface{}, keys ...string) (interface{}, error) {
+	mm, ok := toMap(m)
+	if !ok {
+		return nil, fmt.Errorf("omit: %T is not a map", m)
+	}
+	skip := map[string]bool{}
+	for _, k := range keys {
+		skip[k] = true
+	}
+	res := map[string]interface{}{}
+	for k, v := range mm {
+		if !skip[k] {
+			res[k] = Clone(v)
+		}
+	}
+	return mapLike(m, res), nil
+}
+
+// renameKeys returns a copy of m with keys renamed: renameKeys $m "old" "new" "old2" "new2"
+func renameKeys(m interface{}, pairs ...string) (interface{}, error) {
+	mm, ok := toMap(m)
+	if !ok {
+		return nil, fmt.Errorf("renameKeys: %T is not a map", m)
+	}
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("renameKeys: expected old and new key pairs")
+	}
+	names := map[string]string{}
+	for i := 0; i < len(pairs); i += 2 {
+		names[pairs[i]] = pairs[i+1]
+	}
+	res := map[string]interface{}{}
+	for k, v := range mm {
+		if n, ok := names[k]; ok {
+			k = n
+		}
+		res[k] = Clone(v)
+	}
+	return mapLike(m, res), nil
+}
@@ -0,0 +1,65 @@
+package gotemplate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shoobyban/mxj"
+)
+
+func TestDeepMap(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"setPath": {
+			Template: `{{ $m := setPath .m "a.b.0.c" "v" }}{{ $m }}|{{ .m }}`,
+			Values:   map[string]interface{}{"m": map[string]interface{}{"x": 1}},
+			Result:   "map[a:map[b:[map[c:v]]] x:1]|map[x:1]",
+		},
+		"setPath list": {
+			Template: `{{ setPath .m "items.-1.qty" 5 }}|{{ setPath .m "items.2" "new" }}`,
+			Values:   map[string]interface{}{"m": map[string]interface{}{"items": []interface{}{"a", map[string]interface{}{"qty": 1}}}},
+			Result:   "map[items:[a map[qty:5]]]|map[items:[a map[qty:1] new]]",
+		},
+		"deletePath": {
+			Template: `{{ deletePath .m "a.b" }}|{{ deletePath .m "list.0" }}|{{ .m }}`,
+			Values:   map[string]interface{}{"m": map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": 2}, "list": []interface{}{1, 2}}},
+			Result:   "map[a:map[c:2] list:[1 2]]|map[a:map[b:1 c:2] list:[2]]|map[a:map[b:1 c:2] list:[1 2]]",
+		},
+		"deepMerge": {
+			Template: `{{ deepMerge .a .b }}|{{ deepMerge .a .b "append" }}|{{ deepMerge .a .b "union" }}`,
+			Values: map[string]interface{}{
+				"a": map[string]interface{}{"n": map[string]interface{}{"x": 1, "y": 2}, "l": []interface{}{1, 2}},
+				"b": map[string]interface{}{"n": map[string]interface{}{"y": 3}, "l": []interface{}{2, 3}},
+			},
+			Result: "map[l:[2 3] n:map[x:1 y:3]]|map[l:[1 2 2 3] n:map[x:1 y:3]]|map[l:[1 2 3] n:map[x:1 y:3]]",
+		},
+		"pick omit rename": {
+			Template: `{{ pick .m "a" "c" }}|{{ omit .m "a" }}|{{ renameKeys .m "a" "z" }}`,
+			Values:   map[string]interface{}{"m": mxj.Map{"a": 1, "b": 2, "c": 3}},
+			Result:   "map[a:1 c:3]|map[b:2 c:3]|map[b:2 c:3 z:1]",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	orig := mxj.Map{"a": map[string]interface{}{"b": []interface{}{1, 2}}}
+	c := Clone(orig).(mxj.Map)
+	if !reflect.DeepEqual(orig, c) {
+		t.Errorf("clone differs: %#v != %#v", c, orig)
+	}
+	c["a"].(map[string]interface{})["b"].([]interface{})[0] = 9
+	if orig["a"].(map[string]interface{})["b"].([]interface{})[0] != 1 {
+		t.Errorf("clone shares data with original")
+	}
+	if _, err := SetPath(map[string]interface{}{"a": "x"}, "a.b", 1); err == nil {
+		t.Errorf("SetPath into a string: expected error")
+	}
+}
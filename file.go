@@ -14,25 +14,18 @@ func RegisterFS(filesystem afero.Fs) {
 	fs = filesystem
 }
 
-// ProcessTemplateFile processes golang template file
-func ProcessTemplateFile(template string, bundle interface{}) ([]byte, error) {
-	var byteValue []byte
-	var err error
+// readFile reads a file from the registered filesystem, or from the OS when
+// none is registered
+func readFile(path string) ([]byte, error) {
 	if fs == nil {
-		tf, err := os.Open(template)
-		if err != nil {
-			return nil, err
-		}
-		defer tf.Close()
-		byteValue, err = ioutil.ReadAll(tf)
-	} else {
-		tf, err := fs.Open(template)
-		if err != nil {
-			return nil, err
-		}
-		byteValue, err = ioutil.ReadAll(tf)
-		defer tf.Close()
+		return ioutil.ReadFile(path)
 	}
+	return afero.ReadFile(fs, path)
+}
+
+// ProcessTemplateFile processes golang template file
+func ProcessTemplateFile(template string, bundle interface{}) ([]byte, error) {
+	byteValue, err := readFile(template)
 	if err != nil {
 		return nil, err
 	}
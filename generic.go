@@ -9,7 +9,9 @@ import (
 	"github.com/spf13/cast"
 )
 
-func md5hash(data interface{}) string {
+// md5legacy is the old md5 func, it hashes the Go syntax representation of
+// data (%#v), so "abc" is hashed as "\"abc\""
+func md5legacy(data interface{}) string {
 	s := fmt.Sprintf("%#v", data)
 	return fmt.Sprintf("%x", md5.Sum([]byte(s)))
 }
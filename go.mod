@@ -1,13 +1,33 @@
 module github.com/gpmd/gotemplate
 
 require (
+	github.com/PaesslerAG/gval v1.0.0
+	github.com/PaesslerAG/jsonpath v0.1.1
+	github.com/antchfx/xmlquery v1.5.1
+	github.com/antchfx/xpath v1.3.8
+	github.com/itchyny/gojq v0.12.13
 	github.com/kennygrant/sanitize v1.2.4
 	github.com/recursionpharma/go-csv-map v0.0.0-20160524001940-792523c65ae9
 	github.com/shoobyban/mxj v1.9.1
 	github.com/shoobyban/slog v0.3.0
-	github.com/smartystreets/goconvey v1.6.4 // indirect
+	github.com/shopspring/decimal v1.4.0
 	github.com/spf13/afero v1.2.2
 	github.com/spf13/cast v1.3.1
+	golang.org/x/text v0.21.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/BurntSushi/toml v0.3.0 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
+	github.com/itchyny/timefmt-go v0.1.5 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/natefinch/lumberjack v2.0.0+incompatible // indirect
+	github.com/sirupsen/logrus v1.4.2 // indirect
+	github.com/smartystreets/goconvey v1.6.4 // indirect
+	golang.org/x/net v0.33.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.0.0 // indirect
 )
 
-go 1.13
+go 1.18
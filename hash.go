@@ -0,0 +1,139 @@
+package gotemplate
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"reflect"
+	"strings"
+)
+
+// hashInput is the content hashed and encoded by the funcs: strings and
+// bytes as they are, numbers and Stringers as printed, other values as JSON
+func hashInput(v interface{}) ([]byte, error) {
+	switch d := v.(type) {
+	case nil:
+		return []byte{}, nil
+	case string:
+		return []byte(d), nil
+	case []byte:
+		return d, nil
+	case fmt.Stringer:
+		return []byte(d.String()), nil
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Ptr:
+		return json.Marshal(v)
+	}
+	return []byte(fmt.Sprint(v)), nil
+}
+
+func hexDigest(h hash.Hash, v interface{}) (string, error) {
+	b, err := hashInput(v)
+	if err != nil {
+		return "", err
+	}
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// md5hash is the hex MD5 of the content: md5 "abc" => 900150983cd24fb0d6963f7d28e17f72
+func md5hash(v interface{}) (string, error) {
+	return hexDigest(md5.New(), v)
+}
+
+func sha1hash(v interface{}) (string, error) {
+	return hexDigest(sha1.New(), v)
+}
+
+func sha256hash(v interface{}) (string, error) {
+	return hexDigest(sha256.New(), v)
+}
+
+func sha512hash(v interface{}) (string, error) {
+	return hexDigest(sha512.New(), v)
+}
+
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hmacHash is the hex HMAC of msg with key: hmac "sha256" .secret .payload
+func hmacHash(algorithm string, key string, msg interface{}) (string, error) {
+	h, ok := hashAlgorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return "", fmt.Errorf("hmac: unknown algorithm %s", algorithm)
+	}
+	return hexDigest(hmac.New(h, []byte(key)), msg)
+}
+
+// crc32hash is the IEEE CRC-32 checksum as 8 hex digits
+func crc32hash(v interface{}) (string, error) {
+	b, err := hashInput(v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE(b)), nil
+}
+
+func encodeWith(encode func([]byte) string) func(v interface{}) (string, error) {
+	return func(v interface{}) (string, error) {
+		b, err := hashInput(v)
+		if err != nil {
+			return "", err
+		}
+		return encode(b), nil
+	}
+}
+
+var (
+	base64Encode    = encodeWith(base64.StdEncoding.EncodeToString)
+	base64urlEncode = encodeWith(base64.RawURLEncoding.EncodeToString)
+	hexEncode       = encodeWith(hex.EncodeToString)
+	base32Encode    = encodeWith(base32.StdEncoding.EncodeToString)
+)
+
+func base64Decode(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return "", fmt.Errorf("base64Decode: %v", err)
+	}
+	return string(b), nil
+}
+
+// base64urlDecode decodes URL safe base64 with or without padding
+func base64urlDecode(s string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(s), "="))
+	if err != nil {
+		return "", fmt.Errorf("base64urlDecode: %v", err)
+	}
+	return string(b), nil
+}
+
+func hexDecode(s string) (string, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return "", fmt.Errorf("hexDecode: %v", err)
+	}
+	return string(b), nil
+}
+
+func base32Decode(s string) (string, error) {
+	b, err := base32.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return "", fmt.Errorf("base32Decode: %v", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,45 @@
+package gotemplate
+
+import (
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"digests": {
+			Template: `{{ md5 "abc" }}|{{ sha1 "abc" }}|{{ crc32 "abc" }}|{{ md5 123 }}`,
+			Result:   "900150983cd24fb0d6963f7d28e17f72|a9993e364706816aba3e25717850c26c9cd0d89d|352441c2|202cb962ac59075b964b07152d234b70",
+		},
+		"sha2": {
+			Template: `{{ sha256 "abc" }}|{{ "abc" | sha512 | len }}`,
+			Result:   "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad|128",
+		},
+		"hmac": {
+			Template: `{{ hmac "sha256" "key" "The quick brown fox jumps over the lazy dog" }}|{{ hmac "MD5" "key" "The quick brown fox jumps over the lazy dog" }}`,
+			Result:   "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8|80070713463e7749b90c2dc24911e275",
+		},
+		"encode": {
+			Template: `{{ base64 "hello?>" }}|{{ base64url "hello?>" }}|{{ hex "hi" }}|{{ base32 "hi" }}|{{ base64 .m }}`,
+			Values:   map[string]interface{}{"m": map[string]interface{}{"b": 1, "a": "x"}},
+			Result:   "aGVsbG8/Pg==|aGVsbG8_Pg|6869|NBUQ====|eyJhIjoieCIsImIiOjF9",
+		},
+		"decode": {
+			Template: `{{ base64Decode "aGVsbG8/Pg==" }}|{{ base64urlDecode "aGVsbG8_Pg" }}|{{ base64urlDecode "aGVsbG8_Pg==" }}|{{ hexDecode "6869" }}|{{ base32Decode "NBUQ====" }}`,
+			Result:   "hello?>|hello?>|hello?>|hi|hi",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	for _, tmpl := range []string{`{{ hexDecode "zz" }}`, `{{ base64Decode "***" }}`, `{{ hmac "sha3" "k" "m" }}`} {
+		if _, err := Template(tmpl, nil); err == nil {
+			t.Errorf("%s: expected error", tmpl)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package gotemplate
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HumanizeWords are the words of a locale used by humanizeTime and
+// humanizeDuration
+type HumanizeWords struct {
+	Units map[string][2]string // singular and plural of year, month, week, day, hour, minute, second
+	Short map[string]string    // abbreviations of day, hour, minute, second
+	Ago   string               // "%s ago"
+	In    string               // "in %s"
+	Now   string               // "just now"
+}
+
+var englishWords = HumanizeWords{
+	Units: map[string][2]string{
+		"year": {"year", "years"}, "month": {"month", "months"}, "week": {"week", "weeks"},
+		"day": {"day", "days"}, "hour": {"hour", "hours"}, "minute": {"minute", "minutes"},
+		"second": {"second", "seconds"},
+	},
+	Short: map[string]string{"day": "d", "hour": "h", "minute": "m", "second": "s"},
+	Ago:   "%s ago",
+	In:    "in %s",
+	Now:   "just now",
+}
+
+var humanizeWords = map[string]HumanizeWords{
+	"en": englishWords,
+	"de": {
+		Units: map[string][2]string{
+			"year": {"Jahr", "Jahren"}, "month": {"Monat", "Monaten"}, "week": {"Woche", "Wochen"},
+			"day": {"Tag", "Tagen"}, "hour": {"Stunde", "Stunden"}, "minute": {"Minute", "Minuten"},
+			"second": {"Sekunde", "Sekunden"},
+		},
+		Short: map[string]string{"day": "T", "hour": "Std", "minute": "Min", "second": "s"},
+		Ago:   "vor %s",
+		In:    "in %s",
+		Now:   "gerade eben",
+	},
+}
+
+// RegisterHumanizeWords sets the words humanizeTime and humanizeDuration
+// use for a locale ("fr" or "fr-CA"), missing words fall back to English
+func RegisterHumanizeWords(locale string, words HumanizeWords) {
+	units := map[string][2]string{}
+	for k, v := range englishWords.Units {
+		units[k] = v
+	}
+	for k, v := range words.Units {
+		units[k] = v
+	}
+	short := map[string]string{}
+	for k, v := range englishWords.Short {
+		short[k] = v
+	}
+	for k, v := range words.Short {
+		short[k] = v
+	}
+	words.Units, words.Short = units, short
+	if words.Ago == "" {
+		words.Ago = englishWords.Ago
+	}
+	if words.In == "" {
+		words.In = englishWords.In
+	}
+	if words.Now == "" {
+		words.Now = englishWords.Now
+	}
+	flock.Lock()
+	defer flock.Unlock()
+	humanizeWords[normalizeLocale(locale)] = words
+}
+
+// wordsFor finds the words of the locale by full tag, then language,
+// defaulting to English
+func wordsFor(locale string) HumanizeWords {
+	if locale == "" {
+		locale = getDefaultLocale()
+	}
+	locale = normalizeLocale(locale)
+	flock.Lock()
+	defer flock.Unlock()
+	if w, ok := humanizeWords[locale]; ok {
+		return w
+	}
+	if w, ok := humanizeWords[strings.Split(locale, "-")[0]]; ok {
+		return w
+	}
+	return englishWords
+}
+
+func (w HumanizeWords) unit(name string, n int64) string {
+	forms := w.Units[name]
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, forms[0])
+	}
+	return fmt.Sprintf("%d %s", n, forms[1])
+}
+
+// relative units from largest, months and years are approximate
+var relativeUnits = []struct {
+	name string
+	d    time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"week", 7 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// humanizeTime describes a time relative to now, or to the reference time
+// given before it, in up to precision units (default 1), a leading int being
+// the precision: humanizeTime .created => 3 days ago,
+// humanizeTime $sent .due => in 2 hours, humanizeTime 2 .created => 3 days 2 hours ago
+func humanizeTime(args ...interface{}) (string, error) {
+	return humanizeTimeIn("", args...)
+}
+
+func humanizeTimeIn(locale string, args ...interface{}) (string, error) {
+	precision := 1
+	if len(args) > 1 {
+		if p, ok := args[0].(int); ok {
+			precision, args = p, args[1:]
+		}
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return "", fmt.Errorf("humanizeTime: expected [precision] [reference] time")
+	}
+	if precision < 1 {
+		return "", fmt.Errorf("humanizeTime: precision must be at least 1, got %d", precision)
+	}
+	t, err := toTime(args[len(args)-1])
+	if err != nil {
+		return "", err
+	}
+	ref := now()
+	if len(args) == 2 {
+		if ref, err = toTime(args[0]); err != nil {
+			return "", err
+		}
+	}
+	w := wordsFor(locale)
+	d := t.Sub(ref)
+	format := w.In
+	if d < 0 {
+		d, format = -d, w.Ago
+	}
+	parts := []string{}
+	for _, u := range relativeUnits {
+		if len(parts) == precision {
+			break
+		}
+		if n := int64(d / u.d); n >= 1 {
+			parts = append(parts, w.unit(u.name, n))
+			d -= time.Duration(n) * u.d
+		}
+	}
+	if len(parts) == 0 {
+		return w.Now, nil
+	}
+	return fmt.Sprintf(format, strings.Join(parts, " ")), nil
+}
+
+// humanizeDuration writes a duration with up to precision units (default
+// 2): humanizeDuration "80m" => 1h 20m, humanizeDuration 3 .took => 1d 2h 5m
+func humanizeDuration(args ...interface{}) (string, error) {
+	return humanizeDurationIn("", args...)
+}
+
+func humanizeDurationIn(locale string, args ...interface{}) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", fmt.Errorf("humanizeDuration: expected [precision] duration")
+	}
+	d, err := toDuration(args[len(args)-1])
+	if err != nil {
+		return "", err
+	}
+	precision := 2
+	if len(args) == 2 {
+		if precision, err = intArg("humanizeDuration", args[0]); err != nil {
+			return "", err
+		}
+		if precision < 1 {
+			return "", fmt.Errorf("humanizeDuration: precision must be at least 1, got %d", precision)
+		}
+	}
+	w := wordsFor(locale)
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	parts := []string{}
+	for _, u := range relativeUnits[3:] {
+		if len(parts) == precision {
+			break
+		}
+		if n := int64(d / u.d); n > 0 {
+			parts = append(parts, strconv.FormatInt(n, 10)+w.Short[u.name])
+			d -= time.Duration(n) * u.d
+		}
+	}
+	if len(parts) == 0 {
+		return "0" + w.Short["second"], nil
+	}
+	return sign + strings.Join(parts, " "), nil
+}
+
+// toDuration converts durations, duration strings and numbers of seconds
+func toDuration(v interface{}) (time.Duration, error) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, nil
+	case string:
+		return parseDuration(d)
+	}
+	if f, ok := toNumber(v); ok {
+		return time.Duration(math.Round(f * float64(time.Second))), nil
+	}
+	return 0, fmt.Errorf("can't convert %T to duration", v)
+}
+
+var durationPart = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)\s*(ns|us|µs|ms|s|m|h|d|w)`)
+
+// parseDuration is time.ParseDuration that also knows days and weeks and
+// allows spaces: parseDuration "1w 2d 3h"
+func parseDuration(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+	sign := time.Duration(1)
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	}
+	if rest == "" {
+		return 0, fmt.Errorf("parseDuration: invalid duration %q", s)
+	}
+	var total time.Duration
+	for rest != "" {
+		m := durationPart.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, fmt.Errorf("parseDuration: invalid duration %q", s)
+		}
+		rest = strings.TrimSpace(rest[len(m[0]):])
+		var unit time.Duration
+		switch m[2] {
+		case "w":
+			unit = 7 * 24 * time.Hour
+		case "d":
+			unit = 24 * time.Hour
+		default:
+			one, _ := time.ParseDuration("1" + m[2])
+			unit = one
+		}
+		f, _ := strconv.ParseFloat(m[1], 64)
+		total += time.Duration(f * float64(unit))
+	}
+	return sign * total, nil
+}
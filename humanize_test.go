@@ -0,0 +1,77 @@
+package gotemplate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+	RegisterClock(NewFakeClock(time.Date(2017, 3, 31, 12, 0, 0, 0, time.UTC), 0))
+	defer RegisterClock(nil)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+	tests := map[string]testTemplateStruct{
+		"humanizeTime": {
+			Template: `{{ humanizeTime "2017-03-28 10:00:00" }}|{{ humanizeTime "2017-03-31 14:30:00" }}|{{ humanizeTime "2017-03-31 11:59:59" }}|{{ humanizeTime "2017-03-31 11:59:59.5" }}|{{ humanizeTime "2017-03-01" "2016-01-01" }}`,
+			Result:   "3 days ago|in 2 hours|1 second ago|just now|1 year ago",
+		},
+		"humanizeTime precision": {
+			Template: `{{ humanizeTime 2 "2017-03-28 10:00:00" }}|{{ humanizeTime 3 "2017-03-31 14:30:00" }}|{{ humanizeTime 2 "2017-03-01" "2016-01-01" }}|{{ humanizeTime 2 "2017-03-31 11:59:59.5" }}`,
+			Result:   "3 days 2 hours ago|in 2 hours 30 minutes|1 year 2 months ago|just now",
+		},
+		"humanizeDuration": {
+			Template: `{{ humanizeDuration "80m" }}|{{ humanizeDuration 3 "1d 2h 5m 7s" }}|{{ humanizeDuration 90 }}|{{ humanizeDuration .d }}|{{ humanizeDuration "0s" }}`,
+			Values:   map[string]interface{}{"d": -90 * time.Minute},
+			Result:   "1h 20m|1d 2h 5m|1m 30s|-1h 30m|0s",
+		},
+		"parseDuration": {
+			Template: `{{ parseDuration "1w 2d" }}|{{ parseDuration "1.5h" }}|{{ parseDuration "-2d12h" }}|{{ parseDuration "250ms" }}`,
+			Result:   "216h0m0s|1h30m0s|-60h0m0s|250ms",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := parseDuration("3 fortnights"); err == nil {
+		t.Errorf("parseDuration: expected error")
+	}
+	for _, tmpl := range []string{`{{ humanizeDuration 0 "80m" }}`, `{{ humanizeTime 0 "2017-03-28" }}`, `{{ humanizeTime 2 "2017-03-28" "2017-03-29" "2017-03-30" }}`} {
+		if _, err := Template(tmpl, nil); err == nil {
+			t.Errorf("%s: expected error", tmpl)
+		}
+	}
+}
+
+func TestHumanizeWords(t *testing.T) {
+	RegisterClock(NewFakeClock(time.Date(2017, 3, 31, 12, 0, 0, 0, time.UTC), 0))
+	defer RegisterClock(nil)
+	defer SetDefaultLocale("en")
+	SetDefaultLocale("de-DE")
+	res, _ := Template(`{{ humanizeTime "2017-03-28T10:00:00Z" }}|{{ humanizeDuration "80m" }}`, nil)
+	if want := "vor 3 Tagen|1Std 20Min"; res != want {
+		t.Errorf("de: %#v != %#v", res, want)
+	}
+	RegisterHumanizeWords("fr", HumanizeWords{
+		Units: map[string][2]string{"day": {"jour", "jours"}},
+		Ago:   "il y a %s",
+		In:    "dans %s",
+		Now:   "à l'instant",
+	})
+	SetDefaultLocale("fr-FR")
+	res, _ = Template(`{{ humanizeTime "2017-03-28T10:00:00Z" }}|{{ humanizeTime "2017-03-31T14:00:00Z" }}`, nil)
+	if want := "il y a 3 jours|dans 2 hours"; res != want {
+		t.Errorf("fr: %#v != %#v", res, want)
+	}
+	RegisterHumanizeWords("es", HumanizeWords{Units: map[string][2]string{"day": {"día", "días"}}})
+	SetDefaultLocale("es")
+	res, _ = Template(`{{ humanizeTime "2017-03-28T10:00:00Z" }}|{{ humanizeTime "2017-03-31T12:00:00Z" }}|{{ humanizeDuration "26h" }}`, nil)
+	if want := "3 días ago|just now|1d 2h"; res != want {
+		t.Errorf("es: %#v != %#v", res, want)
+	}
+}
@@ -0,0 +1,466 @@
+package gotemplate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// catalogs holds the messages of each locale by key. A message is a string
+// or a map selecting by plural category ("one", "few", "other"...), exact
+// count ("=0") or gender ("male", "female", "other"), nested as needed.
+var catalogs = map[string]map[string]interface{}{}
+
+var selectorKeys = map[string]bool{
+	"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true,
+	"male": true, "female": true,
+}
+
+// AddMessages adds messages to the catalog of locale, nested maps are
+// flattened to dotted keys: {"cart": {"empty": "..."}} is "cart.empty"
+func AddMessages(locale string, messages map[string]interface{}) {
+	flat := map[string]interface{}{}
+	flattenMessages("", messages, flat)
+	locale = normalizeLocale(locale)
+	flock.Lock()
+	defer flock.Unlock()
+	if catalogs[locale] == nil {
+		catalogs[locale] = map[string]interface{}{}
+	}
+	for k, v := range flat {
+		catalogs[locale][k] = v
+	}
+}
+
+// ResetMessages removes all loaded message catalogs
+func ResetMessages() {
+	flock.Lock()
+	defer flock.Unlock()
+	catalogs = map[string]map[string]interface{}{}
+}
+
+// LoadCatalog adds the messages of a JSON, YAML or gettext .po file on the
+// registered filesystem (see RegisterFS) to the catalog of locale
+func LoadCatalog(locale, path string) error {
+	content, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	messages := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &messages)
+	case ".yaml", ".yml":
+		var m map[interface{}]interface{}
+		if err = yaml.Unmarshal(content, &m); err == nil {
+			messages = stringKeys(m).(map[string]interface{})
+		}
+	case ".po":
+		messages, err = parsePO(content, pluralRuleFor(locale).forms)
+	default:
+		err = fmt.Errorf("unknown catalog format")
+	}
+	if err != nil {
+		return fmt.Errorf("LoadCatalog %s: %v", path, err)
+	}
+	AddMessages(locale, messages)
+	return nil
+}
+
+// stringKeys turns YAML's map[interface{}]interface{} into string keyed maps
+func stringKeys(v interface{}) interface{} {
+	switch m := v.(type) {
+	case map[interface{}]interface{}:
+		res := map[string]interface{}{}
+		for k, val := range m {
+			res[fmt.Sprint(k)] = stringKeys(val)
+		}
+		return res
+	case []interface{}:
+		for i := range m {
+			m[i] = stringKeys(m[i])
+		}
+	}
+	return v
+}
+
+func flattenMessages(prefix string, messages map[string]interface{}, flat map[string]interface{}) {
+	for k, v := range messages {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok && !isSelector(m) {
+			flattenMessages(key, m, flat)
+			continue
+		}
+		flat[key] = v
+	}
+}
+
+// isSelector tells plural and gender variants from nested keys
+func isSelector(m map[string]interface{}) bool {
+	if _, ok := m["other"]; !ok {
+		return false
+	}
+	for k := range m {
+		if !selectorKeys[k] && !strings.HasPrefix(k, "=") {
+			return false
+		}
+	}
+	return true
+}
+
+// poEntry is a gettext entry being read
+type poEntry struct {
+	ctxt     string
+	id       string
+	plural   bool
+	idPlural string
+	fuzzy    bool
+	strs     map[int]string
+}
+
+// parsePO reads gettext entries, msgid being the key, prefixed by msgctxt
+// and a dot when there is one (msgctxt "cart" msgid "title" is
+// "cart.title"). Plural msgstr[n] are
+// mapped to the CLDR categories of the catalog's language in forms order.
+// Fuzzy and untranslated entries and the header are skipped.
+func parsePO(content []byte, forms []string) (map[string]interface{}, error) {
+	messages := map[string]interface{}{}
+	hasOther := false
+	for _, form := range forms {
+		hasOther = hasOther || form == "other"
+	}
+	e := poEntry{strs: map[int]string{}}
+	add := func() {
+		key := e.id
+		if e.ctxt != "" {
+			key = e.ctxt + "." + e.id
+		}
+		if e.id != "" && !e.fuzzy {
+			if !e.plural && e.strs[0] != "" {
+				messages[key] = e.strs[0]
+			}
+			if e.plural {
+				sel := map[string]interface{}{}
+				for i, form := range forms {
+					if s := e.strs[i]; s != "" {
+						sel[form] = s
+						if !hasOther {
+							// the last form stands in for other where gettext has none
+							sel["other"] = s
+						}
+					}
+				}
+				if len(sel) > 0 {
+					messages[key] = sel
+				}
+			}
+		}
+		e = poEntry{strs: map[int]string{}}
+	}
+	// target is where continuation strings go: "ctxt", "id", "plural", a
+	// msgstr index or ""
+	target := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if len(e.strs) > 0 {
+				add()
+			}
+			target = ""
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				e.fuzzy = true
+			}
+			continue
+		}
+		keyword, value := "", line
+		if !strings.HasPrefix(line, `"`) {
+			keyword, value = line, ""
+			if i := strings.IndexAny(line, " \t"); i >= 0 {
+				keyword, value = line[:i], strings.TrimSpace(line[i:])
+			}
+		}
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		switch {
+		case keyword == "":
+			switch target {
+			case "":
+				return nil, fmt.Errorf("line %d: unexpected string", n)
+			case "ctxt":
+				e.ctxt += s
+			case "id":
+				e.id += s
+			case "plural":
+				e.idPlural += s
+			default:
+				i, _ := strconv.Atoi(target)
+				e.strs[i] += s
+			}
+		case keyword == "msgctxt":
+			if len(e.strs) > 0 {
+				add()
+			}
+			e.ctxt, target = s, "ctxt"
+		case keyword == "msgid":
+			if len(e.strs) > 0 {
+				add()
+			}
+			e.id, target = s, "id"
+		case keyword == "msgid_plural":
+			e.plural, e.idPlural, target = true, s, "plural"
+		case keyword == "msgstr":
+			e.strs[0], target = s, "0"
+		case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+			i, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s", n, keyword)
+			}
+			e.strs[i], target = s, strconv.Itoa(i)
+		default:
+			return nil, fmt.Errorf("line %d: unknown keyword %s", n, keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	add()
+	return messages, nil
+}
+
+// localeChain lists the catalogs to search for locale: the full tag, its
+// language, then the same for the default locale
+func localeChain(locale string) []string {
+	chain := []string{}
+	for _, l := range []string{locale, getDefaultLocale()} {
+		l = normalizeLocale(l)
+		chain = append(chain, l, strings.Split(l, "-")[0])
+	}
+	return chain
+}
+
+// lookupMessage returns the message of key and the locale of the catalog
+// it was found in
+func lookupMessage(chain []string, key string) (interface{}, string, bool) {
+	flock.Lock()
+	defer flock.Unlock()
+	for _, l := range chain {
+		if msg, ok := catalogs[l][key]; ok {
+			return msg, l, true
+		}
+	}
+	return nil, "", false
+}
+
+// messageParams reads the args of t: name and value pairs or a single map
+func messageParams(args []interface{}) (map[string]interface{}, error) {
+	if len(args) == 1 {
+		if m, ok := toMap(args[0]); ok {
+			return m, nil
+		}
+	}
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("t: expected name and value pairs or a map")
+	}
+	params := map[string]interface{}{}
+	for i := 0; i < len(args); i += 2 {
+		params[fmt.Sprint(args[i])] = args[i+1]
+	}
+	return params, nil
+}
+
+// selectMessage picks the variant of msg for the count and gender params:
+// exact count "=N" first, then plural category, then gender, then other
+func selectMessage(locale string, msg interface{}, params map[string]interface{}) string {
+	for {
+		m, ok := msg.(map[string]interface{})
+		if !ok {
+			if msg == nil {
+				return ""
+			}
+			return fmt.Sprint(msg)
+		}
+		next, found := interface{}(nil), false
+		if count, ok := params["count"]; ok {
+			if next, found = m["="+fmt.Sprint(count)]; !found {
+				next, found = m[pluralCategory(locale, count)]
+			}
+		}
+		if gender, ok := params["gender"]; ok && !found {
+			next, found = m[fmt.Sprint(gender)]
+		}
+		if !found {
+			next = m["other"]
+		}
+		msg = next
+	}
+}
+
+var messageParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// translate looks up key in the catalogs of locale and fills in {name}
+// params, keys without a translation are returned as they are
+func translate(locale, key string, args ...interface{}) (string, error) {
+	params, err := messageParams(args)
+	if err != nil {
+		return "", err
+	}
+	if locale == "" {
+		locale = getDefaultLocale()
+	}
+	msg, found, ok := lookupMessage(localeChain(locale), key)
+	if !ok {
+		return key, nil
+	}
+	// a fallback catalog's plurals follow the rule of its own language
+	text := selectMessage(found, msg, params)
+	return messageParam.ReplaceAllStringFunc(text, func(p string) string {
+		if v, ok := params[p[1:len(p)-1]]; ok {
+			return fmt.Sprint(v)
+		}
+		return p
+	}), nil
+}
+
+// translateMsg translates a message key to the default locale:
+// t "cart.items" "count" 3 => 3 items, t "greeting" "name" .Name "gender" .Gender
+func translateMsg(key string, args ...interface{}) (string, error) {
+	return translate("", key, args...)
+}
+
+// localeFuncs are the template funcs bound to a per-render locale
+func localeFuncs(locale string) template.FuncMap {
+	orDefault := func(l string) string {
+		if l == "" {
+			return locale
+		}
+		return l
+	}
+	return template.FuncMap{
+		"t": func(key string, args ...interface{}) (string, error) {
+			return translate(locale, key, args...)
+		},
+		"formatNumber": func(l string, decimals int, v interface{}) (string, error) {
+			return FormatNumber(orDefault(l), decimals, v)
+		},
+		"formatCurrency": func(l, currency string, v interface{}) (string, error) {
+			return FormatCurrency(orDefault(l), currency, v)
+		},
+		"formatPercent": func(l string, decimals int, v interface{}) (string, error) {
+			return FormatPercent(orDefault(l), decimals, v)
+		},
+		"humanizeTime": func(args ...interface{}) (string, error) {
+			return humanizeTimeIn(locale, args...)
+		},
+		"humanizeDuration": func(args ...interface{}) (string, error) {
+			return humanizeDurationIn(locale, args...)
+		},
+		"titleCase": func(args ...string) (string, error) {
+			return titleCaseIn(locale, args...)
+		},
+	}
+}
+
+// ExtractMessageKeys lists the keys used with t in a template, sorted.
+// Funcs are not checked, so templates using app registered funcs parse too.
+func ExtractMessageKeys(tmpl string) ([]string, error) {
+	tree := parse.New("extract")
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(tmpl, "", "", trees); err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for _, tt := range trees {
+		collectMessageKeys(tt.Root, keys)
+	}
+	res := make([]string, 0, len(keys))
+	for k := range keys {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func collectMessageKeys(node parse.Node, keys map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, c := range n.Nodes {
+				collectMessageKeys(c, keys)
+			}
+		}
+	case *parse.ActionNode:
+		collectMessageKeys(n.Pipe, keys)
+	case *parse.IfNode:
+		collectMessageKeys(n.Pipe, keys)
+		collectMessageKeys(n.List, keys)
+		collectMessageKeys(n.ElseList, keys)
+	case *parse.RangeNode:
+		collectMessageKeys(n.Pipe, keys)
+		collectMessageKeys(n.List, keys)
+		collectMessageKeys(n.ElseList, keys)
+	case *parse.WithNode:
+		collectMessageKeys(n.Pipe, keys)
+		collectMessageKeys(n.List, keys)
+		collectMessageKeys(n.ElseList, keys)
+	case *parse.TemplateNode:
+		collectMessageKeys(n.Pipe, keys)
+	case *parse.ChainNode:
+		// (t "key").field
+		collectMessageKeys(n.Node, keys)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok && id.Ident == "t" {
+				if len(cmd.Args) > 1 {
+					if s, ok := cmd.Args[1].(*parse.StringNode); ok {
+						keys[s.Text] = true
+					}
+				} else if i > 0 && len(n.Cmds[i-1].Args) == 1 {
+					// "key" | t
+					if s, ok := n.Cmds[i-1].Args[0].(*parse.StringNode); ok {
+						keys[s.Text] = true
+					}
+				}
+			}
+			for _, arg := range cmd.Args {
+				collectMessageKeys(arg, keys)
+			}
+		}
+	}
+}
+
+// MissingTranslations returns the keys that have no message in the catalog
+// of locale or its language
+func MissingTranslations(locale string, keys []string) []string {
+	locale = normalizeLocale(locale)
+	chain := []string{locale, strings.Split(locale, "-")[0]}
+	missing := []string{}
+	for _, k := range keys {
+		if _, _, ok := lookupMessage(chain, k); !ok {
+			missing = append(missing, k)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,205 @@
+package gotemplate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+const testCatalogJSON = `{
+	"cart": {
+		"items": {"=0": "Your cart is empty", "one": "{count} item", "other": "{count} items"},
+		"title": "Cart"
+	},
+	"greeting": {"male": "Welcome back, Mr {name}", "female": "Welcome back, Ms {name}", "other": "Welcome back, {name}"}
+}`
+
+const testCatalogYAML = `
+cart:
+  items:
+    one: "{count} Artikel"
+    other: "{count} Artikel"
+  title: Warenkorb
+greeting:
+  other: "Willkommen zurück, {name}"
+`
+
+const testCatalogPO = `# Polish
+msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "cart.items"
+msgid_plural "cart.items"
+msgstr[0] "{count} produkt"
+msgstr[1] "{count} produkty"
+msgstr[2] "{count} produktów"
+
+#, fuzzy
+msgid "cart.title"
+msgstr "Koszyk?"
+
+msgid "greeting"
+msgstr ""
+"Witaj ponownie, "
+"{name}"
+`
+
+func loadTestCatalogs(t *testing.T) {
+	memfs := afero.NewMemMapFs()
+	afero.WriteFile(memfs, "en.json", []byte(testCatalogJSON), 0644)
+	afero.WriteFile(memfs, "de.yaml", []byte(testCatalogYAML), 0644)
+	afero.WriteFile(memfs, "pl.po", []byte(testCatalogPO), 0644)
+	RegisterFS(memfs)
+	defer RegisterFS(nil)
+	for locale, path := range map[string]string{"en": "en.json", "de": "de.yaml", "pl": "pl.po"} {
+		if err := LoadCatalog(locale, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer ResetMessages()
+	loadTestCatalogs(t)
+	tmpl := `{{ t "cart.title" }}|{{ t "cart.items" "count" 0 }}|{{ t "cart.items" "count" 1 }}|{{ t "cart.items" "count" 22 }}|{{ t "cart.items" "count" 25 }}|{{ t "greeting" "name" .name "gender" .gender }}|{{ t "unknown.key" }}`
+	values := map[string]interface{}{"name": "Smith", "gender": "female"}
+	tests := map[string]string{
+		"":      "Cart|Your cart is empty|1 item|22 items|25 items|Welcome back, Ms Smith|unknown.key",
+		"de-AT": "Warenkorb|0 Artikel|1 Artikel|22 Artikel|25 Artikel|Willkommen zurück, Smith|unknown.key",
+		"pl":    "Cart|0 produktów|1 produkt|22 produkty|25 produktów|Witaj ponownie, Smith|unknown.key",
+	}
+	for locale, want := range tests {
+		res, err := TemplateWithOptions(tmpl, values, Options{Locale: locale})
+		if err != nil {
+			t.Errorf("%s: %v", locale, err)
+		}
+		if res != want {
+			t.Errorf("%s: %#v != %#v", locale, res, want)
+		}
+	}
+	res, err := TemplateWithOptions(`{{ formatNumber "" 2 1234.5 }}|{{ t "cart.items" .params }}`, map[string]interface{}{"params": map[string]interface{}{"count": 3}}, Options{Locale: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "1.234,50|3 Artikel" {
+		t.Errorf("per-render locale: %#v", res)
+	}
+	if _, err := Template(`{{ t "cart.items" "count" }}`, nil); err == nil {
+		t.Errorf("t: expected error for odd params")
+	}
+	// messages from the English fallback follow English plurals, where 0 is
+	// other, not French ones, where it is one
+	AddMessages("en", map[string]interface{}{"points": map[string]interface{}{"one": "{count} point", "other": "{count} points"}})
+	res, err = TemplateWithOptions(`{{ t "points" "count" 0 }}|{{ t "points" "count" 1 }}`, nil, Options{Locale: "fr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "0 points|1 point" {
+		t.Errorf("fallback plurals: %#v", res)
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := map[string][]string{
+		"en": {"0:other", "1:one", "1.5:other", "2:other"},
+		"fr": {"0:one", "1:one", "1.5:one", "2:other"},
+		"ru": {"1:one", "21:one", "11:many", "3:few", "13:many", "5:many", "1.5:other"},
+		"pl": {"1:one", "21:many", "22:few", "12:many", "0:many"},
+		"cs": {"1:one", "3:few", "5:other", "1.5:many"},
+		"ja": {"1:other"},
+	}
+	for locale, cases := range tests {
+		for _, c := range cases {
+			parts := strings.Split(c, ":")
+			n, want := parts[0], parts[1]
+			if got := pluralCategory(locale, n); got != want {
+				t.Errorf("%s %s: %s != %s", locale, n, got, want)
+			}
+		}
+	}
+}
+
+func TestParsePO(t *testing.T) {
+	defer ResetMessages()
+	po := `msgctxt ""
+"checkout"
+msgid "title"
+msgstr "Pokladna"
+
+msgctxt "cart"
+msgid "title"
+msgstr "Košík"
+
+msgid "litres"
+msgid_plural "litres"
+msgstr[0] "{count} litr"
+msgstr[1] "{count} litry"
+msgstr[2] "{count} litrů"
+
+msgid ""
+"bottles "
+"left"
+msgid_plural ""
+"bottles "
+"left"
+msgstr[0] ""
+"zbývá {count} "
+"láhev"
+msgstr[1] "zbývají {count} láhve"
+msgstr[2] "zbývá {count} lahví"
+`
+	messages, err := parsePO([]byte(po), pluralRuleFor("cs").forms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AddMessages("cs", messages)
+	res, err := TemplateWithOptions(`{{ t "checkout.title" }}|{{ t "cart.title" }}|{{ t "litres" "count" 1 }}|{{ t "litres" "count" 3 }}|{{ t "litres" "count" 5 }}|{{ t "litres" "count" 1.5 }}|{{ t "bottles left" "count" 1 }}`, nil, Options{Locale: "cs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Pokladna|Košík|1 litr|3 litry|5 litrů|1.5 litrů|zbývá 1 láhev"; res != want {
+		t.Errorf("%s != %s", res, want)
+	}
+}
+
+func TestPluralForms(t *testing.T) {
+	// every category a rule chooses must be one of its forms, other being
+	// the last form where gettext has none
+	for locale, rule := range pluralRules {
+		forms := map[string]bool{}
+		for _, f := range rule.forms {
+			forms[f] = true
+		}
+		for _, n := range []string{"0", "1", "2", "3", "5", "11", "12", "21", "22", "25", "101", "0.5", "1.5", "2.25"} {
+			if c := rule.choose(newPluralOperands(n)); !forms[c] && c != "other" {
+				t.Errorf("%s %s: %s is not one of %v", locale, n, c, rule.forms)
+			}
+		}
+	}
+}
+
+func TestExtractMessageKeys(t *testing.T) {
+	defer ResetMessages()
+	loadTestCatalogs(t)
+	keys, err := ExtractMessageKeys(`{{ t "cart.title" }}{{ if .x }}{{ t "cart.items" "count" (len .items) }}{{ else }}{{ "checkout.empty" | t }}{{ end }}{{ define "sub" }}{{ range .y }}{{ upper (t "greeting") }}{{ end }}{{ end }}{{ (t "order.status").x }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"cart.items", "cart.title", "checkout.empty", "greeting", "order.status"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys: %#v != %#v", keys, want)
+	}
+	if missing := MissingTranslations("pl-PL", keys); !reflect.DeepEqual(missing, []string{"cart.title", "checkout.empty", "order.status"}) {
+		t.Errorf("missing: %#v", missing)
+	}
+	keys, err = ExtractMessageKeys(`{{ appPrice .sku | t "price" "amount" }}{{ t "footer" | appShout }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"footer", "price"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("unknown funcs: %#v != %#v", keys, want)
+	}
+}
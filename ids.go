@@ -0,0 +1,195 @@
+package gotemplate
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"strings"
+	"sync"
+)
+
+// cryptoSource is a math/rand source reading crypto/rand
+type cryptoSource struct{}
+
+func (cryptoSource) Seed(int64) {}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	rand.Read(b[:])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+var random = struct {
+	sync.Mutex
+	r *mrand.Rand
+}{r: mrand.New(cryptoSource{})}
+
+// SetRandomSource sets the source of uuid, randomString and randomInt, e.g.
+// rand.NewSource(42) for reproducible output in tests. nil restores the
+// default crypto/rand source.
+func SetRandomSource(src mrand.Source) {
+	if src == nil {
+		src = cryptoSource{}
+	}
+	random.Lock()
+	defer random.Unlock()
+	random.r = mrand.New(src)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	random.Lock()
+	defer random.Unlock()
+	random.r.Read(b)
+	return b
+}
+
+func formatUUID(b []byte) string {
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func setVersion(b []byte, version byte) {
+	b[6] = b[6]&0x0f | version<<4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+}
+
+// uuid returns a random v4 UUID, or a time ordered v7 one with
+// uuid "v7"
+func uuid(version ...interface{}) (string, error) {
+	v := "4"
+	if len(version) > 0 {
+		v = strings.TrimPrefix(fmt.Sprint(version[0]), "v")
+	}
+	b := randomBytes(16)
+	switch v {
+	case "4":
+		setVersion(b, 4)
+	case "7":
+		ms := uint64(getClock().Now().UnixNano() / 1e6)
+		for i := 0; i < 6; i++ {
+			b[i] = byte(ms >> uint(40-8*i))
+		}
+		setVersion(b, 7)
+	default:
+		return "", fmt.Errorf("uuid: unsupported version %s, use uuidv5 for v5", v)
+	}
+	return formatUUID(b), nil
+}
+
+var uuidNamespaces = map[string]string{
+	"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+	"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+	"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+}
+
+// uuidv5 is the name based UUID of name in namespace, a UUID or one of dns,
+// url, oid and x500: uuidv5 "url" "https://example.com/order/1"
+func uuidv5(namespace, name string) (string, error) {
+	if ns, ok := uuidNamespaces[strings.ToLower(namespace)]; ok {
+		namespace = ns
+	}
+	ns, err := hex.DecodeString(strings.Replace(namespace, "-", "", -1))
+	if err != nil || len(ns) != 16 {
+		return "", fmt.Errorf("uuidv5: invalid namespace %s", namespace)
+	}
+	h := sha1.New()
+	h.Write(ns)
+	h.Write([]byte(name))
+	b := h.Sum(nil)[:16]
+	setVersion(b, 5)
+	return formatUUID(b), nil
+}
+
+var charsets = map[string]string{
+	"alnum":      "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	"alpha":      "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"upper":      "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"upperalnum": "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+	"digits":     "0123456789",
+	"hex":        "0123456789abcdef",
+}
+
+// randomString returns length random characters of alnum (default), alpha,
+// upper, upperalnum, digits, hex or the given characters:
+// randomString 8 "digits"
+func randomString(length int, charset ...string) (string, error) {
+	chars := charsets["alnum"]
+	if len(charset) > 0 {
+		chars = charset[0]
+		if c, ok := charsets[chars]; ok {
+			chars = c
+		}
+	}
+	runes := []rune(chars)
+	if len(runes) == 0 || length < 0 {
+		return "", fmt.Errorf("randomString: invalid length or charset")
+	}
+	res := make([]rune, length)
+	random.Lock()
+	defer random.Unlock()
+	for i := range res {
+		res[i] = runes[random.r.Intn(len(runes))]
+	}
+	return string(res), nil
+}
+
+// randomInt returns a random integer from min to max inclusive
+func randomInt(min, max int) (int, error) {
+	if max < min {
+		return 0, fmt.Errorf("randomInt: max %d is less than min %d", max, min)
+	}
+	// the span of the widest ranges only fits in uint64, 0 being all of it
+	span := uint64(max) - uint64(min) + 1
+	random.Lock()
+	defer random.Unlock()
+	var r uint64
+	switch {
+	case span == 0:
+		r = random.r.Uint64()
+	case span <= math.MaxInt64:
+		r = uint64(random.r.Int63n(int64(span)))
+	default:
+		// more than half of the values are in the span
+		for r = random.r.Uint64(); r >= span; r = random.r.Uint64() {
+		}
+	}
+	return int(uint64(min) + r), nil
+}
+
+var counters = struct {
+	sync.Mutex
+	values map[string]int64
+}{values: map[string]int64{}}
+
+// SetCounter sets a named counter, the next counter call returns value+1
+func SetCounter(name string, value int64) {
+	counters.Lock()
+	defer counters.Unlock()
+	counters.values[name] = value
+}
+
+// ResetCounters sets all named counters back to zero
+func ResetCounters() {
+	counters.Lock()
+	defer counters.Unlock()
+	counters.values = map[string]int64{}
+}
+
+// counter increments a named counter and returns it, counters keep their
+// value across renders: counter "segment" => 1, 2, 3...
+func counter(name string) int64 {
+	counters.Lock()
+	defer counters.Unlock()
+	counters.values[name]++
+	return counters.values[name]
+}
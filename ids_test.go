@@ -0,0 +1,87 @@
+package gotemplate
+
+import (
+	"math"
+	"math/rand"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestUUID(t *testing.T) {
+	v4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	v7 := regexp.MustCompile(`^017b6300-c500-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	RegisterClock(NewFakeClock(time.Date(2021, 8, 20, 10, 0, 0, 0, time.UTC), 0))
+	defer RegisterClock(nil)
+	res, err := Template(`{{ uuid }} {{ uuid "v7" }} {{ uuidv5 "dns" "www.example.com" }} {{ uuidv5 "6ba7b811-9dad-11d1-80b4-00c04fd430c8" "https://example.com" }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := regexp.MustCompile(` `).Split(res, -1)
+	if !v4.MatchString(ids[0]) {
+		t.Errorf("v4: %s", ids[0])
+	}
+	if !v7.MatchString(ids[1]) {
+		t.Errorf("v7: %s", ids[1])
+	}
+	if ids[2] != "2ed6657d-e927-568b-95e1-2665a8aea6a2" {
+		t.Errorf("v5 dns: %s", ids[2])
+	}
+	if ids[3] != "4fd35a71-71ef-5a55-a9d9-aa75c889a6d0" {
+		t.Errorf("v5 url: %s", ids[3])
+	}
+	if _, err := uuidv5("nope", "x"); err == nil {
+		t.Errorf("uuidv5: expected error for invalid namespace")
+	}
+}
+
+func TestSeededRandom(t *testing.T) {
+	defer SetRandomSource(nil)
+	render := func() string {
+		SetRandomSource(rand.NewSource(42))
+		res, err := Template(`{{ uuid }}|{{ randomString 12 }}|{{ randomString 6 "digits" }}|{{ randomString 4 "AB" }}|{{ randomInt 1 6 }}`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	first := render()
+	if second := render(); first != second {
+		t.Errorf("seeded output differs: %s != %s", first, second)
+	}
+	if !regexp.MustCompile(`^[^|]{36}\|[A-Za-z0-9]{12}\|\d{6}\|[AB]{4}\|[1-6]$`).MatchString(first) {
+		t.Errorf("random output: %s", first)
+	}
+	if _, err := randomInt(5, 1); err == nil {
+		t.Errorf("randomInt: expected error for max < min")
+	}
+}
+
+func TestRandomRanges(t *testing.T) {
+	res, err := Template(`{{ randomString 20 "upper" }}|{{ randomString 20 "upperalnum" }}|{{ randomInt -9223372036854775808 9223372036854775807 }}|{{ randomInt -9223372036854775808 9223372036854775806 }}|{{ randomInt -1 9223372036854775807 }}|{{ randomInt 7 7 }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[A-Z]{20}\|[A-Z0-9]{20}\|-?\d+\|-?\d+\|-?\d+\|7$`).MatchString(res) {
+		t.Errorf("random output: %s", res)
+	}
+	for i := 0; i < 100; i++ {
+		if n, _ := randomInt(-1, math.MaxInt64); n < -1 {
+			t.Fatalf("randomInt: %d out of range", n)
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	defer ResetCounters()
+	SetCounter("batch", 41)
+	for _, want := range []string{"1 2 42", "3 4 43"} {
+		res, err := Template(`{{ counter "seg" }} {{ counter "seg" }} {{ counter "batch" }}`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != want {
+			t.Errorf("counter: %#v != %#v", res, want)
+		}
+	}
+}
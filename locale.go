@@ -0,0 +1,233 @@
+package gotemplate
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// numberLocale holds the CLDR number symbols and patterns of a locale
+type numberLocale struct {
+	decimal       string
+	group         string
+	minGrouping   int    // digits needed before grouping, es and pl write 1234 but 12.345
+	symbolAfter   bool   // 1.234,56 € vs £1,234.56
+	symbolSpace   string // between amount and symbol
+	percentSpace  string // between number and %
+	currencyNames map[string]string
+}
+
+// non-breaking spaces used by CLDR as separators
+const (
+	nbsp  = "\u00a0"
+	nnbsp = "\u202f"
+)
+
+// CLDR number formats of the supported locales, looked up by full tag first,
+// then by language
+var numberLocales = map[string]numberLocale{
+	"en":    {decimal: ".", group: ",", minGrouping: 1},
+	"en-GB": {decimal: ".", group: ",", minGrouping: 1, currencyNames: map[string]string{"USD": "US$"}},
+	"en-IE": {decimal: ".", group: ",", minGrouping: 1, currencyNames: map[string]string{"USD": "US$"}},
+	"en-AU": {decimal: ".", group: ",", minGrouping: 1, currencyNames: map[string]string{"AUD": "$", "USD": "US$"}},
+	"en-CA": {decimal: ".", group: ",", minGrouping: 1, currencyNames: map[string]string{"CAD": "$", "USD": "US$"}},
+	"de":    {decimal: ",", group: ".", minGrouping: 1, symbolAfter: true, symbolSpace: nbsp, percentSpace: nbsp},
+	"de-AT": {decimal: ",", group: nbsp, minGrouping: 1, symbolSpace: nbsp, percentSpace: nbsp},
+	"de-CH": {decimal: ".", group: "’", minGrouping: 1, symbolSpace: nbsp},
+	"fr":    {decimal: ",", group: nnbsp, minGrouping: 1, symbolAfter: true, symbolSpace: nbsp, percentSpace: nnbsp},
+	"fr-CH": {decimal: ",", group: nnbsp, minGrouping: 1, symbolAfter: true, symbolSpace: nbsp},
+	"es":    {decimal: ",", group: ".", minGrouping: 2, symbolAfter: true, symbolSpace: nbsp, percentSpace: nbsp},
+	"it":    {decimal: ",", group: ".", minGrouping: 1, symbolAfter: true, symbolSpace: nbsp},
+	"nl":    {decimal: ",", group: ".", minGrouping: 1, symbolSpace: nbsp},
+	"pt":    {decimal: ",", group: ".", minGrouping: 1, symbolSpace: nbsp},
+	"pt-PT": {decimal: ",", group: nbsp, minGrouping: 2, symbolAfter: true, symbolSpace: nbsp},
+	"pl":    {decimal: ",", group: nbsp, minGrouping: 2, symbolAfter: true, symbolSpace: nbsp},
+	"sv":    {decimal: ",", group: nbsp, minGrouping: 1, symbolAfter: true, symbolSpace: nbsp, percentSpace: nbsp},
+	"da":    {decimal: ",", group: ".", minGrouping: 1, symbolAfter: true, symbolSpace: nbsp, percentSpace: nbsp},
+	"nb":    {decimal: ",", group: nbsp, minGrouping: 1, symbolAfter: true, symbolSpace: nbsp, percentSpace: nbsp},
+	"fi":    {decimal: ",", group: nbsp, minGrouping: 1, symbolAfter: true, symbolSpace: nbsp, percentSpace: nbsp},
+	"cs":    {decimal: ",", group: nbsp, minGrouping: 1, symbolAfter: true, symbolSpace: nbsp, percentSpace: nbsp},
+	"hu":    {decimal: ",", group: nbsp, minGrouping: 1, symbolAfter: true, symbolSpace: nbsp},
+	"ja":    {decimal: ".", group: ",", minGrouping: 1, currencyNames: map[string]string{"JPY": "￥"}},
+	"zh":    {decimal: ".", group: ",", minGrouping: 1, currencyNames: map[string]string{"CNY": "¥"}},
+}
+
+// currencies holds the default symbol and minor unit digits of ISO 4217 codes
+var currencies = map[string]struct {
+	symbol string
+	digits int
+}{
+	"AUD": {"A$", 2}, "BRL": {"R$", 2}, "CAD": {"CA$", 2}, "CHF": {"CHF", 2},
+	"CNY": {"CN¥", 2}, "CZK": {"Kč", 2}, "DKK": {"kr.", 2}, "EUR": {"€", 2},
+	"GBP": {"£", 2}, "HUF": {"Ft", 2}, "INR": {"₹", 2}, "JPY": {"¥", 0},
+	"KRW": {"₩", 0}, "NOK": {"kr", 2}, "NZD": {"NZ$", 2}, "PLN": {"zł", 2},
+	"SEK": {"kr", 2}, "USD": {"$", 2}, "BHD": {"BHD", 3}, "KWD": {"KWD", 3},
+}
+
+// iso4217 lists the other active ISO 4217 currencies by minor unit digits,
+// they are written with their code as symbol
+var iso4217 = map[int]string{
+	0: "BIF CLP DJF GNF ISK KMF PYG RWF UGX UYI VND VUV XAF XOF XPF",
+	2: "AED AFN ALL AMD ANG AOA ARS AWG AZN BAM BBD BDT BGN BMD BND BOB BOV " +
+		"BSD BTN BWP BYN BZD CDF CHE CHW COP COU CRC CUP CVE DOP DZD EGP ERN " +
+		"ETB FJD FKP GEL GHS GIP GMD GTQ GYD HKD HNL HTG IDR ILS IRR JMD KES " +
+		"KGS KHR KPW KYD KZT LAK LBP LKR LRD LSL MAD MDL MGA MKD MMK MNT MOP " +
+		"MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NPR PAB PEN PGK PHP PKR " +
+		"QAR RON RSD RUB SAR SBD SCR SDG SGD SHP SLE SLL SOS SRD SSP STN SVC " +
+		"SYP SZL THB TJS TMT TOP TRY TTD TWD TZS UAH USN UYU UZS VED VES WST " +
+		"XCD XCG YER ZAR ZMW ZWG ZWL",
+	3: "IQD JOD LYD OMR TND",
+	4: "CLF UYW",
+}
+
+// lookupCurrency returns the symbol and minor unit digits of an ISO 4217
+// currency code
+func lookupCurrency(code string) (symbol string, digits int, ok bool) {
+	if cur, ok := currencies[code]; ok {
+		return cur.symbol, cur.digits, true
+	}
+	if len(code) != 3 {
+		return "", 0, false
+	}
+	for digits, codes := range iso4217 {
+		if strings.Contains(" "+codes+" ", " "+code+" ") {
+			return code, digits, true
+		}
+	}
+	return "", 0, false
+}
+
+var defaultLocale = "en"
+
+// SetDefaultLocale sets the locale used by the formatting funcs when they
+// get an empty locale, e.g. "en-GB" or "de_DE"
+func SetDefaultLocale(tag string) error {
+	tag = normalizeLocale(tag)
+	if _, err := lookupNumberLocale(tag); err != nil {
+		return err
+	}
+	flock.Lock()
+	defer flock.Unlock()
+	defaultLocale = tag
+	return nil
+}
+
+func getDefaultLocale() string {
+	flock.Lock()
+	defer flock.Unlock()
+	return defaultLocale
+}
+
+// normalizeLocale turns de_de, DE-de into de-DE
+func normalizeLocale(tag string) string {
+	parts := strings.Split(strings.Replace(strings.TrimSpace(tag), "_", "-", -1), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+	return strings.Join(parts, "-")
+}
+
+func lookupNumberLocale(tag string) (numberLocale, error) {
+	if tag == "" {
+		tag = getDefaultLocale()
+	}
+	tag = normalizeLocale(tag)
+	if loc, ok := numberLocales[tag]; ok {
+		return loc, nil
+	}
+	if loc, ok := numberLocales[strings.Split(tag, "-")[0]]; ok {
+		return loc, nil
+	}
+	return numberLocale{}, fmt.Errorf("Unknown locale %s", tag)
+}
+
+// formatLocaleNumber formats d with the locale's separators, rounding half
+// to even like CLDR does
+func formatLocaleNumber(d decimal.Decimal, decimals int, loc numberLocale) string {
+	d = d.RoundBank(int32(decimals))
+	s := d.Abs().StringFixed(int32(decimals))
+	intPart, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+	if len(intPart) >= 4+loc.minGrouping-1 {
+		grouped := ""
+		for len(intPart) > 3 {
+			grouped = loc.group + intPart[len(intPart)-3:] + grouped
+			intPart = intPart[:len(intPart)-3]
+		}
+		intPart += grouped
+	}
+	if frac != "" {
+		intPart += loc.decimal + frac
+	}
+	if d.IsNegative() {
+		intPart = "-" + intPart
+	}
+	return intPart
+}
+
+// FormatNumber formats v with decimals digits the way locale writes numbers:
+// FormatNumber("de-DE", 2, 1234.5) => 1.234,50
+func FormatNumber(locale string, decimals int, v interface{}) (string, error) {
+	loc, err := lookupNumberLocale(locale)
+	if err != nil {
+		return "", err
+	}
+	d, err := toDecimal(v)
+	if err != nil {
+		return "", fmt.Errorf("formatNumber: %v", err)
+	}
+	return formatLocaleNumber(d, decimals, loc), nil
+}
+
+// FormatCurrency formats v as an amount of the ISO 4217 currency the way
+// locale writes it: FormatCurrency("de-DE", "EUR", 1234.56) => 1.234,56 €
+func FormatCurrency(locale, currency string, v interface{}) (string, error) {
+	loc, err := lookupNumberLocale(locale)
+	if err != nil {
+		return "", err
+	}
+	d, err := toDecimal(v)
+	if err != nil {
+		return "", fmt.Errorf("formatCurrency: %v", err)
+	}
+	currency = strings.ToUpper(currency)
+	symbol, digits, ok := lookupCurrency(currency)
+	if !ok {
+		return "", fmt.Errorf("formatCurrency: unknown currency %q", currency)
+	}
+	if sym, ok := loc.currencyNames[currency]; ok {
+		symbol = sym
+	}
+	num := formatLocaleNumber(d, digits, loc)
+	space := loc.symbolSpace
+	if space == "" && !loc.symbolAfter && unicode.IsLetter([]rune(symbol)[len([]rune(symbol))-1]) {
+		// CLDR currency spacing: CHF 12.00 but £12.00
+		space = nbsp
+	}
+	if loc.symbolAfter {
+		return num + space + symbol, nil
+	}
+	if strings.HasPrefix(num, "-") && space == "" {
+		return "-" + symbol + num[1:], nil
+	}
+	return symbol + space + num, nil
+}
+
+// FormatPercent formats a ratio as percentage the way locale writes it:
+// FormatPercent("en-GB", 1, 0.125) => 12.5%
+func FormatPercent(locale string, decimals int, v interface{}) (string, error) {
+	loc, err := lookupNumberLocale(locale)
+	if err != nil {
+		return "", err
+	}
+	d, err := toDecimal(v)
+	if err != nil {
+		return "", fmt.Errorf("formatPercent: %v", err)
+	}
+	return formatLocaleNumber(d.Shift(2), decimals, loc) + loc.percentSpace + "%", nil
+}
@@ -0,0 +1,60 @@
+package gotemplate
+
+import (
+	"testing"
+)
+
+func TestLocaleFormat(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"formatNumber": {
+			Template: `{{ formatNumber "de-DE" 2 1234.5 }}|{{ formatNumber "en_GB" 0 1234567 }}|{{ formatNumber "fr" 2 "-1234.5" }}|{{ formatNumber "es" 2 1234.56 }}|{{ formatNumber "es" 0 12345 }}`,
+			Result:   "1.234,50|1,234,567|-1\u202f234,50|1234,56|12.345",
+		},
+		"formatCurrency": {
+			Template: `{{ formatCurrency "de-DE" "EUR" 1234.56 }}|{{ formatCurrency "en-GB" "GBP" -1234.5 }}|{{ formatCurrency "en-GB" "CHF" 12 }}|{{ formatCurrency "en" "JPY" 1234.5 }}|{{ formatCurrency "en-GB" "USD" 1 }}`,
+			Result:   "1.234,56\u00a0€|-£1,234.50|CHF\u00a012.00|¥1,234|US$1.00",
+		},
+		"formatCurrency ISO codes": {
+			Template: `{{ formatCurrency "en-GB" "zar" 12 }}|{{ formatCurrency "en" "OMR" 1.5 }}|{{ formatCurrency "en" "CLP" 1234 }}`,
+			Result:   "ZAR\u00a012.00|OMR\u00a01.500|CLP\u00a01,234",
+		},
+		"formatPercent": {
+			Template: `{{ formatPercent "en-GB" 1 0.125 }}|{{ formatPercent "de" 0 "0.5" }}`,
+			Result:   "12.5%|50\u00a0%",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := FormatNumber("xx-YY", 2, 1); err == nil {
+		t.Errorf("FormatNumber: expected error for unknown locale")
+	}
+	for _, code := range []string{"", "EU", "XYZ"} {
+		if _, err := FormatCurrency("en", code, 1); err == nil {
+			t.Errorf("FormatCurrency: expected error for currency %q", code)
+		}
+	}
+}
+
+func TestDefaultLocale(t *testing.T) {
+	defer SetDefaultLocale("en")
+	if err := SetDefaultLocale("xx"); err == nil {
+		t.Errorf("SetDefaultLocale: expected error for unknown locale")
+	}
+	if err := SetDefaultLocale("de_de"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Template(`{{ formatNumber "" 2 1234.5 }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "1.234,50" {
+		t.Errorf("default locale: %#v != %#v", res, "1.234,50")
+	}
+}
@@ -1,12 +1,357 @@
 package gotemplate
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
-// add returns the sum of a and b.
-func add(b, a interface{}) (interface{}, error) {
+var numberSeparators = struct {
+	decimal, group string
+}{".", ""}
+
+// SetNumberSeparators sets the decimal and grouping separators used when
+// math funcs parse numeric strings, e.g. SetNumberSeparators(",", ".") to
+// read "1.234,56" as 1234.56. The default is "." and no grouping.
+func SetNumberSeparators(decimalSep, groupSep string) {
+	flock.Lock()
+	defer flock.Unlock()
+	numberSeparators.decimal = decimalSep
+	numberSeparators.group = groupSep
+}
+
+// normalizeNumber rewrites a numeric string using the configured separators
+// to Go syntax
+func normalizeNumber(s string) string {
+	flock.Lock()
+	sep := numberSeparators
+	flock.Unlock()
+	s = strings.TrimSpace(s)
+	if sep.group != "" {
+		s = strings.Replace(s, sep.group, "", -1)
+	}
+	if sep.decimal != "." && sep.decimal != "" {
+		s = strings.Replace(s, sep.decimal, ".", -1)
+	}
+	return s
+}
+
+// numericArg converts numeric strings to int64 or float64, other values are
+// returned as they are
+func numericArg(fname string, v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.String {
+		return v, nil
+	}
+	s := normalizeNumber(rv.String())
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("%s: %q is not a number", fname, rv.String())
+}
+
+func numericArgs(fname string, a, b interface{}) (interface{}, interface{}, error) {
+	a, err := numericArg(fname, a)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err = numericArg(fname, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+func isZero(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	}
+	return false
+}
+
+// foldNumbers applies op to the last argument (the piped value) and each
+// other argument in turn: sub 1 2 10 => 10 - 1 - 2
+func foldNumbers(fname string, op func(b, a interface{}) (interface{}, error), args []interface{}) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("%s: needs at least two arguments", fname)
+	}
+	acc := args[len(args)-1]
+	for _, b := range args[:len(args)-1] {
+		var err error
+		if acc, err = op(b, acc); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+// add returns the sum of all arguments: add 1 2 3 => 6
+func add(args ...interface{}) (interface{}, error) {
+	return foldNumbers("add", addValues, args)
+}
+
+// subtract subtracts all other arguments from the last one: sub 1 2 10 => 7
+func subtract(args ...interface{}) (interface{}, error) {
+	return foldNumbers("subtract", subtractValues, args)
+}
+
+// multiply returns the product of all arguments
+func multiply(args ...interface{}) (interface{}, error) {
+	return foldNumbers("multiply", multiplyValues, args)
+}
+
+// divide divides the last argument by all other ones: div 2 5 100 => 10
+func divide(args ...interface{}) (interface{}, error) {
+	return foldNumbers("divide", divideValues, args)
+}
+
+// modulo returns the remainder of a divided by b: mod 3 10 => 1
+func modulo(b, a interface{}) (interface{}, error) {
+	if isDecimal(a) || isDecimal(b) {
+		ad, err := toDecimal(a)
+		if err != nil {
+			return nil, fmt.Errorf("mod: %v", err)
+		}
+		bd, err := toDecimal(b)
+		if err != nil {
+			return nil, fmt.Errorf("mod: %v", err)
+		}
+		if bd.IsZero() {
+			return nil, errors.New("mod: division by zero")
+		}
+		return ad.Mod(bd), nil
+	}
+	a, b, err := numericArgs("mod", a, b)
+	if err != nil {
+		return nil, err
+	}
+	if isZero(b) {
+		return nil, errors.New("mod: division by zero")
+	}
+	ai, aok := toInt64(a)
+	bi, bok := toInt64(b)
+	if aok && bok {
+		return ai % bi, nil
+	}
+	af, aok := toNumber(a)
+	bf, bok := toNumber(b)
+	if !aok || !bok {
+		return nil, fmt.Errorf("mod: unknown type for %v (%T) or %v (%T)", a, a, b, b)
+	}
+	return math.Mod(af, bf), nil
+}
+
+// power returns a to the power of b: pow 2 10 => 100
+func power(b, a interface{}) (interface{}, error) {
+	if isDecimal(a) {
+		ad, _ := toDecimal(a)
+		bd, err := toDecimal(b)
+		if err != nil {
+			return nil, fmt.Errorf("pow: %v", err)
+		}
+		if err := powDomain(ad.Sign(), bd.Sign(), bd.IsInteger()); err != nil {
+			return nil, err
+		}
+		if ad.IsZero() && bd.IsZero() {
+			// 0^0 is 1 like math.Pow, decimal.Pow returns 0
+			return decimal.NewFromInt(1), nil
+		}
+		return ad.Pow(bd), nil
+	}
+	a, b, err := numericArgs("pow", a, b)
+	if err != nil {
+		return nil, err
+	}
+	ai, aok := toInt64(a)
+	bi, bok := toInt64(b)
+	if aok && bok && bi >= 0 {
+		if res, ok := intPow(ai, bi); ok {
+			return res, nil
+		}
+		// too large for int64, use float
+	}
+	af, aok := toNumber(a)
+	bf, bok := toNumber(b)
+	if !aok || !bok {
+		return nil, fmt.Errorf("pow: unknown type for %v (%T) or %v (%T)", a, a, b, b)
+	}
+	if err := powDomain(sign(af), sign(bf), bf == math.Trunc(bf)); err != nil {
+		return nil, err
+	}
+	return math.Pow(af, bf), nil
+}
+
+// powDomain rejects 0 to a negative power and roots of negative numbers
+func powDomain(baseSign, expSign int, expInteger bool) error {
+	if baseSign == 0 && expSign < 0 {
+		return fmt.Errorf("pow: division by zero")
+	}
+	if baseSign < 0 && !expInteger {
+		return fmt.Errorf("pow: negative base with non-integer exponent")
+	}
+	return nil
+}
+
+func sign(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	}
+	return 0
+}
+
+// intPow is base to the power of exp by squaring, false on int64 overflow
+func intPow(base, exp int64) (int64, bool) {
+	res := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			var ok bool
+			if res, ok = mulInt64(res, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			var ok bool
+			if base, ok = mulInt64(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return res, true
+}
+
+// mulInt64 multiplies, false on overflow
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	res := a * b
+	if res/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return res, true
+}
+
+// toInt64 returns integer kinds as int64
+func toInt64(v interface{}) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+// intArg converts integers, whole floats and numeric strings to int
+func intArg(fname string, v interface{}) (int, error) {
+	v, err := numericArg(fname, v)
+	if err != nil {
+		return 0, err
+	}
+	if i, ok := toInt64(v); ok {
+		return int(i), nil
+	}
+	if f, ok := toNumber(v); ok && f == math.Trunc(f) {
+		return int(f), nil
+	}
+	return 0, fmt.Errorf("%s: %v is not an integer", fname, v)
+}
+
+// floor rounds down to an integer, keeping integers and decimals as they are
+func floor(v interface{}) (interface{}, error) {
+	return roundInteger("floor", v, math.Floor, decimal.Decimal.Floor)
+}
+
+// ceil rounds up to an integer, keeping integers and decimals as they are
+func ceil(v interface{}) (interface{}, error) {
+	return roundInteger("ceil", v, math.Ceil, decimal.Decimal.Ceil)
+}
+
+func roundInteger(fname string, v interface{}, f func(float64) float64, d func(decimal.Decimal) decimal.Decimal) (interface{}, error) {
+	if isDecimal(v) {
+		dv, _ := toDecimal(v)
+		return d(dv), nil
+	}
+	v, err := numericArg(fname, v)
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := toInt64(v); ok {
+		return i, nil
+	}
+	fv, ok := toNumber(v)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown type for %v (%T)", fname, v, v)
+	}
+	return f(fv), nil
+}
+
+// clamp limits v to the range lo..hi: clamp 0 100 .percent
+func clamp(lo, hi, v interface{}) (interface{}, error) {
+	vals := []interface{}{lo, hi, v}
+	for i := range vals {
+		var err error
+		if isDecimal(vals[i]) {
+			continue
+		}
+		if vals[i], err = numericArg("clamp", vals[i]); err != nil {
+			return nil, err
+		}
+		if _, ok := toNumber(vals[i]); !ok {
+			return nil, fmt.Errorf("clamp: unknown type for %v (%T)", vals[i], vals[i])
+		}
+	}
+	lo, hi, v = vals[0], vals[1], vals[2]
+	if compareNumbers(lo, hi) > 0 {
+		return nil, fmt.Errorf("clamp: %v is greater than %v", lo, hi)
+	}
+	if compareNumbers(v, lo) < 0 {
+		return lo, nil
+	}
+	if compareNumbers(v, hi) > 0 {
+		return hi, nil
+	}
+	return v, nil
+}
+
+// compareNumbers compares numbers exactly, decimals included
+func compareNumbers(a, b interface{}) int {
+	ad, aerr := toDecimal(a)
+	bd, berr := toDecimal(b)
+	if aerr == nil && berr == nil {
+		return ad.Cmp(bd)
+	}
+	return compareValues(a, b)
+}
+
+// addValues returns the sum of a and b.
+func addValues(b, a interface{}) (interface{}, error) {
+	if isDecimal(a) || isDecimal(b) {
+		return decimalArith("add", a, b)
+	}
+	a, b, err := numericArgs("add", a, b)
+	if err != nil {
+		return nil, err
+	}
 	av := reflect.ValueOf(a)
 	bv := reflect.ValueOf(b)
 
@@ -49,8 +394,15 @@ func add(b, a interface{}) (interface{}, error) {
 	}
 }
 
-// subtract returns the difference of b from a.
-func subtract(b, a interface{}) (interface{}, error) {
+// subtractValues returns the difference of b from a.
+func subtractValues(b, a interface{}) (interface{}, error) {
+	if isDecimal(a) || isDecimal(b) {
+		return decimalArith("subtract", a, b)
+	}
+	a, b, err := numericArgs("subtract", a, b)
+	if err != nil {
+		return nil, err
+	}
 	av := reflect.ValueOf(a)
 	bv := reflect.ValueOf(b)
 
@@ -93,8 +445,15 @@ func subtract(b, a interface{}) (interface{}, error) {
 	}
 }
 
-// multiply returns the product of a and b.
-func multiply(b, a interface{}) (interface{}, error) {
+// multiplyValues returns the product of a and b.
+func multiplyValues(b, a interface{}) (interface{}, error) {
+	if isDecimal(a) || isDecimal(b) {
+		return decimalArith("multiply", a, b)
+	}
+	a, b, err := numericArgs("multiply", a, b)
+	if err != nil {
+		return nil, err
+	}
 	av := reflect.ValueOf(a)
 	bv := reflect.ValueOf(b)
 
@@ -137,8 +496,18 @@ func multiply(b, a interface{}) (interface{}, error) {
 	}
 }
 
-// divide returns the division of b from a.
-func divide(b, a interface{}) (interface{}, error) {
+// divideValues returns the division of b from a.
+func divideValues(b, a interface{}) (interface{}, error) {
+	if isDecimal(a) || isDecimal(b) {
+		return decimalArith("divide", a, b)
+	}
+	a, b, err := numericArgs("divide", a, b)
+	if err != nil {
+		return nil, err
+	}
+	if isZero(b) {
+		return nil, errors.New("divide: division by zero")
+	}
 	av := reflect.ValueOf(a)
 	bv := reflect.ValueOf(b)
 
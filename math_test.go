@@ -0,0 +1,73 @@
+package gotemplate
+
+import "testing"
+
+func TestMath(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"strings": {
+			Template: `{{ add "1" "2.5" }}|{{ sub "1" .a }}|{{ mul "3" 2 }}|{{ div "4" "10.0" }}`,
+			Values:   map[string]interface{}{"a": "10"},
+			Result:   "3.5|9|6|2.5",
+		},
+		"variadic": {
+			Template: `{{ add 1 2 3 }}|{{ sub 1 2 10 }}|{{ mul 2 3 4 }}|{{ div 2 5 100 }}`,
+			Result:   "6|7|24|10",
+		},
+		"mod pow": {
+			Template: `{{ mod 3 10 }}|{{ mod "2.5" 7.5 }}|{{ pow 2 10 }}|{{ 2 | pow 10 }}|{{ pow "0.5" 9 }}`,
+			Result:   "1|0|100|1024|3",
+		},
+		"pow large": {
+			Template: `{{ pow 1000000000000000000 1 }}|{{ pow 1000000000000000001 -1 }}|{{ pow 62 2 }}|{{ pow 63 2 }}|{{ pow 64 2 }}|{{ pow 1000000000000000000 2 }}`,
+			Result:   "1|-1|4611686018427387904|9.223372036854776e+18|1.8446744073709552e+19|+Inf",
+		},
+		"pow edge cases": {
+			Template: `{{ pow 0 0 }}|{{ pow 0 (dec "0") }}|{{ pow 3 -2 }}|{{ pow 3 (dec "-2") }}|{{ pow -1 2 }}|{{ pow (dec "2") (dec "0") }}`,
+			Result:   "1|1|-8|-8|0.5|0",
+		},
+		"floor ceil": {
+			Template: `{{ floor "2.7" }}|{{ ceil 2.1 }}|{{ floor -2.5 }}|{{ ceil 3 }}|{{ floor (dec "2.99") }}`,
+			Result:   "2|3|-3|3|2",
+		},
+		"min max clamp": {
+			Template: `{{ min 3 "1.5" 2 }}|{{ max 3 "1.5" 2 }}|{{ clamp 0 100 "120" }}|{{ clamp 0 100 -5 }}|{{ clamp 0 100 "42" }}`,
+			Result:   "1.5|3|100|0|42",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	errTests := map[string]string{
+		"int division by zero":   `{{ div 0 10 }}`,
+		"float division by zero": `{{ div 0.0 1.5 }}`,
+		"mod by zero":            `{{ mod 0 10 }}`,
+		"not a number":           `{{ add "x" 1 }}`,
+		"pow zero to negative":   `{{ pow -1 0 }}`,
+		"pow negative root":      `{{ pow "0.5" -4 }}`,
+		"pow decimal zero":       `{{ pow -1 (dec "0") }}`,
+		"pow decimal root":       `{{ pow "0.5" (dec "-4") }}`,
+	}
+	for name, tmpl := range errTests {
+		if _, err := Template(tmpl, nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestNumberSeparators(t *testing.T) {
+	SetNumberSeparators(",", ".")
+	defer SetNumberSeparators(".", "")
+	res, err := Template(`{{ add "1.234,5" "0,5" }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "1235" {
+		t.Errorf("%#v != %#v", res, "1235")
+	}
+}
@@ -0,0 +1,113 @@
+package gotemplate
+
+import (
+	"strings"
+)
+
+// pluralOperands are the CLDR operands of a number: i is the integer part,
+// v the number of visible fraction digits
+type pluralOperands struct {
+	i int64
+	v int
+}
+
+func newPluralOperands(n interface{}) pluralOperands {
+	d, err := toDecimal(n)
+	if err != nil {
+		return pluralOperands{}
+	}
+	s := d.Abs().String()
+	o := pluralOperands{i: d.Abs().IntPart()}
+	if p := strings.Index(s, "."); p >= 0 {
+		o.v = len(s) - p - 1
+	}
+	return o
+}
+
+// pluralRule selects the CLDR cardinal category of a number, forms lists
+// the categories in the order of gettext's msgstr[n]
+type pluralRule struct {
+	forms  []string
+	choose func(o pluralOperands) string
+}
+
+var (
+	ruleOne = pluralRule{[]string{"one", "other"}, func(o pluralOperands) string {
+		if o.i == 1 && o.v == 0 {
+			return "one"
+		}
+		return "other"
+	}}
+	ruleZeroOne = pluralRule{[]string{"one", "other"}, func(o pluralOperands) string {
+		if o.i == 0 || o.i == 1 {
+			return "one"
+		}
+		return "other"
+	}}
+	ruleOther = pluralRule{[]string{"other"}, func(o pluralOperands) string {
+		return "other"
+	}}
+	ruleEastSlavic = pluralRule{[]string{"one", "few", "many"}, func(o pluralOperands) string {
+		switch {
+		case o.v != 0:
+			return "other"
+		case o.i%10 == 1 && o.i%100 != 11:
+			return "one"
+		case o.i%10 >= 2 && o.i%10 <= 4 && (o.i%100 < 12 || o.i%100 > 14):
+			return "few"
+		}
+		return "many"
+	}}
+	rulePolish = pluralRule{[]string{"one", "few", "many"}, func(o pluralOperands) string {
+		switch {
+		case o.v != 0:
+			return "other"
+		case o.i == 1:
+			return "one"
+		case o.i%10 >= 2 && o.i%10 <= 4 && (o.i%100 < 12 || o.i%100 > 14):
+			return "few"
+		}
+		return "many"
+	}}
+	// fractions are many, gettext's optional fourth form
+	ruleCzech = pluralRule{[]string{"one", "few", "other", "many"}, func(o pluralOperands) string {
+		switch {
+		case o.v != 0:
+			return "many"
+		case o.i == 1:
+			return "one"
+		case o.i >= 2 && o.i <= 4:
+			return "few"
+		}
+		return "other"
+	}}
+)
+
+// pluralRules by language, others use the English rule
+var pluralRules = map[string]pluralRule{
+	"en": ruleOne, "de": ruleOne, "nl": ruleOne, "sv": ruleOne, "da": ruleOne,
+	"nb": ruleOne, "fi": ruleOne, "it": ruleOne, "es": ruleOne, "el": ruleOne,
+	"hu": ruleOne, "tr": ruleOne, "pt-PT": ruleOne,
+	"fr": ruleZeroOne, "pt": ruleZeroOne,
+	"ja": ruleOther, "zh": ruleOther, "ko": ruleOther, "vi": ruleOther, "th": ruleOther,
+	"ru": ruleEastSlavic, "uk": ruleEastSlavic,
+	"pl": rulePolish,
+	"cs": ruleCzech, "sk": ruleCzech,
+}
+
+func pluralRuleFor(locale string) pluralRule {
+	locale = normalizeLocale(locale)
+	if r, ok := pluralRules[locale]; ok {
+		return r
+	}
+	if r, ok := pluralRules[strings.Split(locale, "-")[0]]; ok {
+		return r
+	}
+	return ruleOne
+}
+
+// pluralCategory is the CLDR plural category (one, few, many, other...) of n
+// in locale
+func pluralCategory(locale string, n interface{}) string {
+	return pluralRuleFor(locale).choose(newPluralOperands(n))
+}
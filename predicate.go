@@ -0,0 +1,377 @@
+package gotemplate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// predicate is a parsed filter expression like `price>10 and iso in (GB,IE)`
+type predicate interface {
+	match(item interface{}) (bool, error)
+}
+
+type andPredicate []predicate
+
+func (p andPredicate) match(item interface{}) (bool, error) {
+	for _, sub := range p {
+		ok, err := sub.match(item)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+type orPredicate []predicate
+
+func (p orPredicate) match(item interface{}) (bool, error) {
+	for _, sub := range p {
+		ok, err := sub.match(item)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// condition compares the value under key (a dotted path inside the item)
+// with one or more values
+type condition struct {
+	key    string
+	op     string
+	values []interface{}
+	re     *regexp.Regexp
+}
+
+func newCondition(key, op string, values ...interface{}) (*condition, error) {
+	c := &condition{key: key, op: op, values: values}
+	switch op {
+	case "=", "==", "!=", "<", ">", "<=", ">=":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("Operator %s needs one value. [Key:%s]", op, key)
+		}
+	case "~=":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("Operator %s needs one value. [Key:%s]", op, key)
+		}
+		re, err := regexp.Compile(fmt.Sprint(values[0]))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid regular expression. [Key:%s]: %v", key, err)
+		}
+		c.re = re
+	case "in":
+		if len(values) == 1 {
+			if list, ok := toSlice(values[0]); ok {
+				c.values = list
+			}
+		}
+	default:
+		return nil, fmt.Errorf("Unknown operator %s. [Key:%s]", op, key)
+	}
+	return c, nil
+}
+
+func (c *condition) match(item interface{}) (bool, error) {
+	field, found := lookupKey(item, c.key)
+	switch c.op {
+	case "=", "==":
+		return found && equalValues(field, c.values[0]), nil
+	case "!=":
+		return !found || !equalValues(field, c.values[0]), nil
+	case "<":
+		return found && compareValues(field, c.values[0]) < 0, nil
+	case ">":
+		return found && compareValues(field, c.values[0]) > 0, nil
+	case "<=":
+		return found && compareValues(field, c.values[0]) <= 0, nil
+	case ">=":
+		return found && compareValues(field, c.values[0]) >= 0, nil
+	case "~=":
+		return found && field != nil && c.re.MatchString(fmt.Sprint(field)), nil
+	case "in":
+		if !found {
+			return false, nil
+		}
+		for _, v := range c.values {
+			if equalValues(field, v) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// lookupKey returns the value under a dotted key inside maps, the item
+// itself for an empty key
+func lookupKey(item interface{}, key string) (interface{}, bool) {
+	if key == "" || key == "." {
+		return item, true
+	}
+	v := item
+	for _, k := range strings.Split(key, ".") {
+		m, ok := toMap(v)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// toNumber converts numeric kinds and numeric strings to float64
+func toNumber(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equalValues compares numerically when both sides are numbers (or numeric
+// strings), otherwise by their string form
+func equalValues(a, b interface{}) bool {
+	if af, ok := toNumber(a); ok {
+		if bf, ok := toNumber(b); ok {
+			return af == bf
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// compareValues orders numerically when both sides are numbers (or numeric
+// strings), otherwise by their string form
+func compareValues(a, b interface{}) int {
+	if af, ok := toNumber(a); ok {
+		if bf, ok := toNumber(b); ok {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+type predicateToken struct {
+	text   string
+	quoted bool
+}
+
+func (t predicateToken) is(word string) bool {
+	return !t.quoted && strings.EqualFold(t.text, word)
+}
+
+// tokenizePredicate splits a predicate into words, quoted strings,
+// operators, parentheses and commas
+func tokenizePredicate(s string) ([]predicateToken, error) {
+	tokens := []predicateToken{}
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(rs) && rs[end] != r {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("Unterminated string in filter [%s]", s)
+			}
+			tokens = append(tokens, predicateToken{text: string(rs[i+1 : end]), quoted: true})
+			i = end + 1
+		case i+1 < len(rs) && strings.ContainsRune("!<>~=", r) && rs[i+1] == '=':
+			tokens = append(tokens, predicateToken{text: string(rs[i : i+2])})
+			i += 2
+		case strings.ContainsRune("=<>(),", r):
+			tokens = append(tokens, predicateToken{text: string(r)})
+			i++
+		default:
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) && !strings.ContainsRune("=!<>~(),'\"", rs[end]) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("Unexpected %q in filter [%s]", r, s)
+			}
+			tokens = append(tokens, predicateToken{text: string(rs[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type predicateParser struct {
+	src    string
+	tokens []predicateToken
+	pos    int
+}
+
+// parsePredicate parses filter expressions:
+//
+//	key=value, key!=value, key<value, key>=value, key~=regexp, key in (a,b)
+//
+// joined by and / or (and binds tighter), grouped with parentheses
+func parsePredicate(s string) (predicate, error) {
+	tokens, err := tokenizePredicate(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &predicateParser{src: s, tokens: tokens}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return pred, nil
+}
+
+func (p *predicateParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Invalid filter [%s]: %s", p.src, fmt.Sprintf(format, args...))
+}
+
+func (p *predicateParser) peek() (predicateToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return predicateToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *predicateParser) next() (predicateToken, error) {
+	t, ok := p.peek()
+	if !ok {
+		return t, p.errorf("unexpected end")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *predicateParser) parseOr() (predicate, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := orPredicate{first}
+	for t, ok := p.peek(); ok && t.is("or"); t, ok = p.peek() {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, next)
+	}
+	if len(or) == 1 {
+		return first, nil
+	}
+	return or, nil
+}
+
+func (p *predicateParser) parseAnd() (predicate, error) {
+	first, err := p.parseCondition()
+	if err != nil {
+		return nil, err
+	}
+	and := andPredicate{first}
+	for t, ok := p.peek(); ok && t.is("and"); t, ok = p.peek() {
+		p.pos++
+		next, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, next)
+	}
+	if len(and) == 1 {
+		return first, nil
+	}
+	return and, nil
+}
+
+func (p *predicateParser) parseCondition() (predicate, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.is("(") {
+		pred, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, err = p.next(); err != nil || !t.is(")") {
+			return nil, p.errorf("missing )")
+		}
+		return pred, nil
+	}
+	key := t.text
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.quoted {
+		return nil, p.errorf("expected operator after %s", key)
+	}
+	if op.is("in") {
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return newCondition(key, "in", values...)
+	}
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if !value.quoted && strings.ContainsAny(value.text, "(),=<>") {
+		return nil, p.errorf("expected value after %s%s", key, op.text)
+	}
+	c, err := newCondition(key, op.text, value.text)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	return c, nil
+}
+
+func (p *predicateParser) parseList() ([]interface{}, error) {
+	if t, err := p.next(); err != nil || !t.is("(") {
+		return nil, p.errorf("expected ( after in")
+	}
+	values := []interface{}{}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.is(")") && len(values) == 0 {
+			return values, nil
+		}
+		values = append(values, t.text)
+		if t, err = p.next(); err != nil {
+			return nil, err
+		}
+		if t.is(")") {
+			return values, nil
+		}
+		if !t.is(",") {
+			return nil, p.errorf("expected , or ) in list")
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package gotemplate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/itchyny/gojq"
+)
+
+// jsonpath with comparison, arithmetic, logic and regex (=~) in filters
+var jsonpathLang = gval.Full(jsonpath.Language())
+
+// JSONPath evaluates a JSONPath expression (e.g. $..book[?(@.price < 10)].title)
+// against maps and slices from json_decode, ParseStruct or plain Go data.
+// Definite paths return a single value, paths with wildcards, recursive
+// descent, slices or filters return a []interface{} of all matches.
+// String literals in filters use double quotes or backticks.
+func JSONPath(data interface{}, path string) (interface{}, error) {
+	eval, err := jsonpathLang.NewEvaluable(path)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath: invalid path '%s': %v", path, err)
+	}
+	v, err := normalizeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	res, err := eval(context.Background(), v)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath: '%s': %v", path, err)
+	}
+	return res, nil
+}
+
+// JQ runs a jq program against maps and slices from json_decode, ParseStruct
+// or plain Go data and returns all its outputs, so templates can range over
+// them whatever their number
+func JQ(data interface{}, program string) ([]interface{}, error) {
+	q, err := gojq.Parse(program)
+	if err != nil {
+		return nil, fmt.Errorf("jq: invalid program '%s': %v", program, err)
+	}
+	v, err := normalizeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	res := []interface{}{}
+	iter := q.Run(v)
+	for {
+		out, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := out.(error); ok {
+			if herr, ok := err.(haltError); ok && herr.IsHaltError() && herr.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("jq: '%s': %v", program, err)
+		}
+		res = append(res, out)
+	}
+	return res, nil
+}
+
+// haltError is the error of gojq's halt, which ends the program
+type haltError interface {
+	IsHaltError() bool
+	Value() interface{}
+}
+
+// JQFirst returns the first output of a jq program, nil when there is none
+func JQFirst(data interface{}, program string) (interface{}, error) {
+	res, err := JQ(data, program)
+	if err != nil || len(res) == 0 {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+// normalizeJSON converts any JSON compatible value (mxj.Map, csv rows,
+// structs) to plain map[string]interface{} / []interface{} trees, integers
+// stay exact as int and other numbers become float64
+func normalizeJSON(data interface{}) (interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to convert %T to json: %v", data, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return jsonNumbers(v), nil
+}
+
+// jsonNumbers replaces the json.Number values of a decoded tree
+func jsonNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = jsonNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = jsonNumbers(item)
+		}
+	case json.Number:
+		if i, err := strconv.ParseInt(string(val), 10, 0); err == nil {
+			return int(i)
+		}
+		f, _ := val.Float64()
+		return f
+	}
+	return v
+}
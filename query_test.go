@@ -0,0 +1,120 @@
+package gotemplate
+
+import "testing"
+
+type testQueryOrder struct {
+	ID    int64
+	Ref   int64
+	Price float64
+}
+
+var testQueryLarge = testQueryOrder{ID: 12345678, Ref: 9007199254740993, Price: 9.5}
+
+const testQueryJSON = `{"order":{"id":"SO1","items":[
+	{"sku":"A","qty":2,"price":"9.50"},
+	{"sku":"B","qty":1,"price":"120.00"},
+	{"sku":"C","qty":3,"price":"1.25"}]}}`
+
+func TestJSONPath(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"single": {
+			Template: `{{ jsonpath (json_decode .json) "$.order.id" }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "SO1",
+		},
+		"wildcard": {
+			Template: `{{ range jsonpath (json_decode .json) "$.order.items[*].sku" }}{{.}}{{ end }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "ABC",
+		},
+		"recursive": {
+			Template: `{{ jsonpath (json_decode .json) "$..qty" }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "[2 1 3]",
+		},
+		"slice": {
+			Template: `{{ jsonpath (json_decode .json) "$.order.items[1:].sku" }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "[B C]",
+		},
+		"filter": {
+			Template: `{{ jsonpath (json_decode .json) "$.order.items[?(@.qty >= 2)].sku" }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "[A C]",
+		},
+		"csv rows": {
+			Template: `{{ jsonpath .rows "$[?(@.code == \"GB\")].name" }}`,
+			Values: map[string]interface{}{"rows": []map[string]string{
+				{"code": "GB", "name": "Great Britain"},
+				{"code": "US", "name": "United States"},
+			}},
+			Result: "[Great Britain]",
+		},
+		"large integers": {
+			Template: `{{ jsonpath .v "$.ID" }} {{ jsonpath .v "$.Ref" }} {{ jsonpath .v "$.Price" }}`,
+			Values:   map[string]interface{}{"v": testQueryLarge},
+			Result:   "12345678 9007199254740993 9.5",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+}
+
+func TestJQ(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"single": {
+			Template: `{{ jqFirst (json_decode .json) ".order.id" }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "SO1",
+		},
+		"multiple": {
+			Template: `{{ range jq (json_decode .json) ".order.items[] | select(.qty > 1) | .sku" }}{{.}}{{ end }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "AC",
+		},
+		"range one": {
+			Template: `{{ range jq (json_decode .json) ".order.id" }}{{.}};{{ end }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "SO1;",
+		},
+		"reduce": {
+			Template: `{{ jqFirst (json_decode .json) "[.order.items[].qty] | add" }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "6",
+		},
+		"empty": {
+			Template: `{{ range jq (json_decode .json) ".order.items[] | select(.qty > 5)" }}{{.}}{{ end }}{{ jqFirst (json_decode .json) ".order.items[] | select(.qty > 5)" }}`,
+			Values:   map[string]interface{}{"json": testQueryJSON},
+			Result:   "",
+		},
+		"struct": {
+			Template: `{{ jqFirst .v ".Name | ascii_upcase" }}`,
+			Values:   map[string]interface{}{"v": struct{ Name string }{"widget"}},
+			Result:   "WIDGET",
+		},
+		"large integers": {
+			Template: `{{ jqFirst .v ".ID" }} {{ range jq .v ".Ref, .Price" }}{{.}} {{ end }}{{ jqFirst .v ".ID + 1" }}`,
+			Values:   map[string]interface{}{"v": testQueryLarge},
+			Result:   "12345678 9007199254740993 9.5 12345679",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := JQ(map[string]interface{}{}, ".a |"); err == nil {
+		t.Errorf("invalid program: expected error")
+	}
+}
@@ -0,0 +1,219 @@
+package gotemplate
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"text/template"
+)
+
+// lruCache keeps the most recently used values by key
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *lruEntry, most recent first
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// regexps are the compiled patterns and replaceTemplates the parsed
+// reReplaceFunc templates
+var (
+	regexps          = newLRUCache(256)
+	replaceTemplates = newLRUCache(256)
+)
+
+// SetRegexCacheSize sets how many compiled patterns and reReplaceFunc
+// templates the regex funcs keep (256 by default), 0 disables caching
+func SetRegexCacheSize(n int) {
+	for _, c := range []*lruCache{regexps, replaceTemplates} {
+		c.mu.Lock()
+		c.size = n
+		c.trim()
+		c.mu.Unlock()
+	}
+}
+
+func (c *lruCache) trim() {
+	for c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*lruEntry).key)
+	}
+}
+
+// get returns the cached value of key, or builds and caches it
+func (c *lruCache) get(key string, build func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*lruEntry).value, nil
+	}
+	c.mu.Unlock()
+	v, err := build()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && c.size > 0 {
+		c.entries[key] = c.order.PushFront(&lruEntry{key, v})
+		c.trim()
+	}
+	return v, nil
+}
+
+// compileRegexp returns the cached compiled pattern, invalid patterns are
+// errors
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	re, err := regexps.get(pattern, func() (interface{}, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", pattern, err)
+		}
+		return re, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return re.(*regexp.Regexp), nil
+}
+
+func match(pattern, s string) (bool, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
+
+// reReplaceAll replaces matches, $1 and ${name} expand to groups:
+// reReplaceAll "(\\d+)-(\\d+)" "$2-$1" .range
+func reReplaceAll(pattern, repl, text string) (string, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(text, repl), nil
+}
+
+func regReplaceAll(replaceRegex, input string) (string, error) {
+	return reReplaceAll(replaceRegex, "", input)
+}
+
+// reFind returns the first match or ""
+func reFind(pattern, s string) (string, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(s), nil
+}
+
+// reFindAll returns all matches: reFindAll "\\d+" "a1b22" => [1 22]
+func reFindAll(pattern, s string) ([]string, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	res := re.FindAllString(s, -1)
+	if res == nil {
+		res = []string{}
+	}
+	return res, nil
+}
+
+// groupMap maps the groups of a match by number and name, "0" being the
+// whole match
+func groupMap(re *regexp.Regexp, groups []string) map[string]string {
+	m := map[string]string{}
+	for i, g := range groups {
+		m[strconv.Itoa(i)] = g
+		if name := re.SubexpNames()[i]; name != "" {
+			m[name] = g
+		}
+	}
+	return m
+}
+
+// reSubmatch returns the groups of the first match by number and name, or
+// an empty map: (reSubmatch "(?P<year>\\d{4})-(?P<month>\\d\\d)" .date).year
+func reSubmatch(pattern, s string) (map[string]string, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	groups := re.FindStringSubmatch(s)
+	if groups == nil {
+		return map[string]string{}, nil
+	}
+	return groupMap(re, groups), nil
+}
+
+// reSplit splits s around the matches: reSplit "\\s*,\\s*" "a , b,c" => [a b c]
+func reSplit(pattern, s string) ([]string, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.Split(s, -1), nil
+}
+
+// reReplaceFuncWith makes reReplaceFunc for a render, its templates use
+// the funcs of the render
+func reReplaceFuncWith(funcs template.FuncMap) func(pattern, tmpl, s string) (string, error) {
+	return func(pattern, tmpl, s string) (string, error) {
+		return reReplaceFunc(funcs, pattern, tmpl, s)
+	}
+}
+
+// reReplaceFunc replaces each match with the output of a template run on
+// the groups of the match, by number (index . "1") or name:
+// reReplaceFunc "(?P<qty>\\d+)x" "{{ mul 2 .qty }}x" "3x apples" => 6x apples
+func reReplaceFunc(funcs template.FuncMap, pattern, tmpl, s string) (string, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := replaceTemplates.get(tmpl, func() (interface{}, error) {
+		return template.New("reReplaceFunc").Funcs(funcs).Parse(tmpl)
+	})
+	if err != nil {
+		return "", err
+	}
+	// the cached template may have been parsed with the funcs of another render
+	t, err := parsed.(*template.Template).Clone()
+	if err != nil {
+		return "", err
+	}
+	t.Funcs(funcs)
+	var b bytes.Buffer
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+		groups := make([]string, len(loc)/2)
+		for i := range groups {
+			if loc[2*i] >= 0 {
+				groups[i] = s[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.WriteString(s[last:loc[0]])
+		if err := t.Execute(&b, groupMap(re, groups)); err != nil {
+			return "", err
+		}
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
@@ -0,0 +1,87 @@
+package gotemplate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegex(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"find": {
+			Template: `{{ reFind "\\d+" "ab12c345" }}|{{ reFindAll "\\d+" "ab12c345" }}|{{ reFindAll "x" "abc" }}|{{ match "^[A-Z]{2}\\d+$" "GB123" }}`,
+			Result:   "12|[12 345]|[]|true",
+		},
+		"submatch": {
+			Template: `{{ $m := reSubmatch "(?P<year>\\d{4})-(?P<month>\\d\\d)-(\\d\\d)" "on 2017-03-31" }}{{ $m.year }}/{{ $m.month }}/{{ index $m "3" }}|{{ len (reSubmatch "x" "abc") }}`,
+			Result:   "2017/03/31|0",
+		},
+		"split": {
+			Template: `{{ reSplit "\\s*[,;]\\s*" "a , b;c" }}`,
+			Result:   "[a b c]",
+		},
+		"replace": {
+			Template: `{{ reReplaceAll "(\\d+)-(\\d+)" "$2-$1" "10-20" }}|{{ regexpReplace "[^a-z]" "a1b2" }}`,
+			Result:   "20-10|ab",
+		},
+		"replaceFunc": {
+			Template: `{{ reReplaceFunc "(?P<qty>\\d+)x" "{{ mul 2 .qty }}x" "3x apples, 10x pears" }}|{{ reReplaceFunc "\\b(\\w)" "{{ upper (index . \"1\") }}" "big red bus" }}`,
+			Result:   "6x apples, 20x pears|Big Red Bus",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	for _, tmpl := range []string{`{{ reReplaceAll "(" "" "x" }}`, `{{ match "[" "x" }}`, `{{ reFindAll "a**" "x" }}`, `{{ regexpReplace "(?P<" "x" }}`} {
+		if _, err := Template(tmpl, nil); err == nil {
+			t.Errorf("%s: expected error for invalid pattern", tmpl)
+		}
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	defer SetRegexCacheSize(256)
+	SetRegexCacheSize(2)
+	for _, p := range []string{"a", "b", "c", "b"} {
+		if _, err := compileRegexp(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(regexps.entries) != 2 || regexps.entries["a"] != nil || regexps.order.Front().Value.(*lruEntry).key != "b" {
+		t.Errorf("cache holds %d entries", len(regexps.entries))
+	}
+	SetRegexCacheSize(0)
+	if len(regexps.entries) != 0 {
+		t.Errorf("cache not emptied")
+	}
+}
+
+func TestReReplaceFuncRender(t *testing.T) {
+	callback := `{{ formatNumber "" 0 (index . "0") }}`
+	res, err := TemplateWithOptions(`{{ reReplaceFunc "\\d+" .cb "1234 units" }}`, map[string]interface{}{"cb": callback}, Options{Locale: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "1.234 units" {
+		t.Errorf("locale funcs: %s != 1.234 units", res)
+	}
+	if replaceTemplates.entries[callback] == nil {
+		t.Errorf("callback template not cached")
+	}
+	res, err = Template(`{{ reReplaceFunc "\\d+" .cb "1234 units" }}`, map[string]interface{}{"cb": callback})
+	if err != nil || res != "1,234 units" {
+		t.Errorf("cached template with the default locale: %s, %v", res, err)
+	}
+	query, args, err := RenderSQL(`id = {{ reReplaceFunc "\\d+" "{{ param (index . \"0\") }}" "5 or id = 7" }}`, nil, Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "id = $1 or id = $2" || !reflect.DeepEqual(args, []interface{}{"5", "7"}) {
+		t.Errorf("RenderSQL funcs: %s %v", query, args)
+	}
+}
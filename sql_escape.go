@@ -1,48 +1,234 @@
 package gotemplate
 
 import (
+	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// provided template func
-func sqlEscape(q interface{}) string {
-	if q == nil {
-		return "NULL"
+// SQL dialects of sql, sqlIdent and RenderSQL
+const (
+	MySQL     = "mysql"
+	Postgres  = "postgres"
+	SQLite    = "sqlite"
+	SQLServer = "sqlserver"
+)
+
+// sqlDialect holds how a database writes literals and identifiers
+type sqlDialect struct {
+	quoteString func(s string) string
+	quoteIdent  func(name string) string
+	boolTrue    string
+	boolFalse   string
+	timeFormat  string
+	placeholder func(n int) string
+	quoteBytes  func(b []byte) string
+}
+
+// doubleQuotes encloses s in open and close, doubling close inside
+func doubleQuotes(s, open, close string) string {
+	return open + strings.Replace(s, close, close+close, -1) + close
+}
+
+func questionMark(int) string { return "?" }
+
+var sqlDialects = map[string]sqlDialect{
+	MySQL: {
+		quoteString: escapeString,
+		quoteIdent:  func(n string) string { return doubleQuotes(n, "`", "`") },
+		boolTrue:    "TRUE",
+		boolFalse:   "FALSE",
+		timeFormat:  "2006-01-02 15:04:05.999999",
+		placeholder: questionMark,
+		quoteBytes:  func(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" },
+	},
+	Postgres: {
+		quoteString: func(s string) string { return doubleQuotes(s, "'", "'") },
+		quoteIdent:  func(n string) string { return doubleQuotes(n, `"`, `"`) },
+		boolTrue:    "TRUE",
+		boolFalse:   "FALSE",
+		timeFormat:  "2006-01-02 15:04:05.999999Z07:00",
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		quoteBytes:  func(b []byte) string { return "'\\x" + hex.EncodeToString(b) + "'" },
+	},
+	SQLite: {
+		quoteString: func(s string) string { return doubleQuotes(s, "'", "'") },
+		quoteIdent:  func(n string) string { return doubleQuotes(n, `"`, `"`) },
+		boolTrue:    "1",
+		boolFalse:   "0",
+		timeFormat:  "2006-01-02 15:04:05.999999999Z07:00",
+		placeholder: questionMark,
+		quoteBytes:  func(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" },
+	},
+	SQLServer: {
+		quoteString: func(s string) string { return "N" + doubleQuotes(s, "'", "'") },
+		quoteIdent:  func(n string) string { return doubleQuotes(n, "[", "]") },
+		boolTrue:    "1",
+		boolFalse:   "0",
+		timeFormat:  "2006-01-02T15:04:05.9999999",
+		placeholder: func(n int) string { return "@p" + strconv.Itoa(n) },
+		quoteBytes:  func(b []byte) string { return "0x" + hex.EncodeToString(b) },
+	},
+}
+
+var defaultSQLDialect = MySQL
+
+// SetSQLDialect sets the dialect sql and sqlIdent use when none is given:
+// mysql (the default), postgres, sqlite or sqlserver
+func SetSQLDialect(dialect string) error {
+	if _, err := lookupSQLDialect(dialect); err != nil {
+		return err
+	}
+	flock.Lock()
+	defer flock.Unlock()
+	defaultSQLDialect = strings.ToLower(dialect)
+	return nil
+}
+
+// SetSQLTimeFormat sets the layout sql writes time.Time values in for a
+// dialect, e.g. "2006-01-02" for date columns
+func SetSQLTimeFormat(dialect, layout string) error {
+	flock.Lock()
+	defer flock.Unlock()
+	d, ok := sqlDialects[strings.ToLower(dialect)]
+	if !ok {
+		return fmt.Errorf("Unknown SQL dialect %s", dialect)
+	}
+	d.timeFormat = layout
+	sqlDialects[strings.ToLower(dialect)] = d
+	return nil
+}
+
+func lookupSQLDialect(name string) (sqlDialect, error) {
+	flock.Lock()
+	defer flock.Unlock()
+	if name == "" {
+		name = defaultSQLDialect
+	}
+	d, ok := sqlDialects[strings.ToLower(name)]
+	if !ok {
+		return sqlDialect{}, fmt.Errorf("Unknown SQL dialect %s", name)
+	}
+	return d, nil
+}
+
+// dialectArgs splits the optional dialect from the piped value
+func dialectArgs(fname string, args []interface{}) (sqlDialect, interface{}, error) {
+	switch len(args) {
+	case 1:
+		d, err := lookupSQLDialect("")
+		return d, args[0], err
+	case 2:
+		name, ok := args[0].(string)
+		if !ok {
+			return sqlDialect{}, nil, fmt.Errorf("%s: dialect must be a string, got %T", fname, args[0])
+		}
+		d, err := lookupSQLDialect(name)
+		return d, args[1], err
 	}
-	return sqlEscapeType(reflect.ValueOf(q))
+	return sqlDialect{}, nil, fmt.Errorf("%s: expected [dialect] value", fname)
 }
 
+// sqlEscape writes a value as SQL literal of the default or given dialect:
+// sql .name, sql "postgres" .ids => 1, 2, 3
+func sqlEscape(args ...interface{}) (string, error) {
+	d, q, err := dialectArgs("sql", args)
+	if err != nil {
+		return "", err
+	}
+	return d.sqlEscapeType(reflect.ValueOf(q))
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
 // sqlEscapeType uses Reflect to detect and handle each different type
 // and escape it accordingly
-func sqlEscapeType(value reflect.Value) string {
-	var escaped string
+func (d sqlDialect) sqlEscapeType(value reflect.Value) (string, error) {
+	if !value.IsValid() {
+		return "NULL", nil
+	}
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		if value.IsNil() {
+			return "NULL", nil
+		}
+	}
+	if value.Type().Implements(valuerType) {
+		v, err := value.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return "", fmt.Errorf("sql: %v", err)
+		}
+		return d.sqlEscapeType(reflect.ValueOf(v))
+	}
+	if t, ok := value.Interface().(time.Time); ok {
+		return d.quoteString(t.Format(d.timeFormat)), nil
+	}
 	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return d.sqlEscapeType(value.Elem())
 	case reflect.String:
-		escaped = escapeString(value.String())
-	case reflect.Slice:
+		return d.quoteString(value.String()), nil
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if value.Kind() == reflect.Slice && value.IsNil() {
+				return "NULL", nil
+			}
+			b := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(b), value)
+			return d.quoteBytes(b), nil
+		}
 		vals := make([]string, 0, value.Len())
 		for i := 0; i < value.Len(); i++ {
-			vals = append(vals, sqlEscapeType(value.Index(i)))
+			v, err := d.sqlEscapeType(value.Index(i))
+			if err != nil {
+				return "", err
+			}
+			vals = append(vals, v)
+		}
+		return strings.Join(vals, ", "), nil
+	case reflect.Bool:
+		if value.Bool() {
+			return d.boolTrue, nil
 		}
-		escaped = strings.Join(vals, ", ")
-	case reflect.Int:
-		escaped = strconv.FormatInt(value.Int(), 10)
-	case reflect.Float32:
-		escaped = strconv.FormatFloat(value.Float(), 'f', -1, 32)
-	case reflect.Float64:
-		escaped = strconv.FormatFloat(value.Float(), 'f', -1, 64)
-	default:
+		return d.boolFalse, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := value.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("sql: cannot write %v as SQL number", f)
+		}
+		return strconv.FormatFloat(f, 'f', -1, value.Type().Bits()), nil
+	case reflect.Map, reflect.Struct:
 		b, err := json.Marshal(value.Interface())
 		if err != nil {
-			panic(err)
+			return "", fmt.Errorf("sql: %v", err)
 		}
-		escaped = sqlEscapeType(reflect.ValueOf(string(b)))
+		return d.quoteString(string(b)), nil
+	}
+	return "", fmt.Errorf("sql: cannot write %s as SQL value", value.Type())
+}
+
+// sqlIdent quotes a table or column name, dotted names part by part:
+// sqlIdent "postgres" "public.order" => "public"."order"
+func sqlIdent(args ...interface{}) (string, error) {
+	d, name, err := dialectArgs("sqlIdent", args)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(fmt.Sprint(name), ".")
+	for i, p := range parts {
+		parts[i] = d.quoteIdent(p)
 	}
-	return escaped
+	return strings.Join(parts, "."), nil
 }
 
 // escapeString, escapes unwanted characters from strings
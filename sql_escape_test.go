@@ -1,6 +1,11 @@
 package gotemplate
 
-import "testing"
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+)
 
 type testSQLTemplateStruct struct {
 	Template string
@@ -40,6 +45,75 @@ func TestSQLEscape(t *testing.T) {
 			Values:   map[string]float64{"a": 1.000001, "b": 2.1, "c": 3.0},
 			Result:   `select * from t where name = '{"a":1.000001,"b":2.1,"c":3}'`,
 		},
+		"postgres string": {
+			Template: `select * from t where name = {{sql "postgres" .}}`,
+			Values:   "it's a\\path\n",
+			Result:   "select * from t where name = 'it''s a\\path\n'",
+		},
+		"sqlite string list": {
+			Template: `select * from t where name in ({{sql "sqlite" .}})`,
+			Values:   []string{"o'neil", "x"},
+			Result:   `select * from t where name in ('o''neil', 'x')`,
+		},
+		"sqlserver string": {
+			Template: `select * from t where name = {{sql "sqlserver" .}}`,
+			Values:   "Zoë's",
+			Result:   `select * from t where name = N'Zoë''s'`,
+		},
+		"int kinds": {
+			Template: `{{sql .}}`,
+			Values:   []interface{}{int8(-8), int64(1) << 40, uint(7), uint64(18446744073709551615)},
+			Result:   `-8, 1099511627776, 7, 18446744073709551615`,
+		},
+		"null": {
+			Template: `{{sql .a}}, {{sql "postgres" .b}}`,
+			Values:   map[string]interface{}{"a": nil, "b": []interface{}{1, nil}},
+			Result:   `NULL, 1, NULL`,
+		},
+		"bool": {
+			Template: `{{sql .}}, {{sql "postgres" .}}, {{sql "sqlite" .}}, {{sql "sqlserver" .}}`,
+			Values:   true,
+			Result:   `TRUE, TRUE, 1, 1`,
+		},
+		"time": {
+			Template: `{{sql .}}, {{sql "postgres" .}}, {{sql "sqlserver" .}}`,
+			Values:   time.Date(2021, 3, 4, 5, 6, 7, 500000000, time.UTC),
+			Result:   `'2021-03-04 05:06:07.5', '2021-03-04 05:06:07.5Z', N'2021-03-04T05:06:07.5'`,
+		},
+		"numeric kinds": {
+			Template: `{{sql .}}`,
+			Values:   []interface{}{float32(0.1), int16(-3), uint8(255), uintptr(9)},
+			Result:   `0.1, -3, 255, 9`,
+		},
+		"pointers": {
+			Template: `{{sql .a}}, {{sql .b}}, {{sql .c}}`,
+			Values:   map[string]interface{}{"a": (*int)(nil), "b": &[]int{4}[0], "c": []*string{nil}},
+			Result:   `NULL, 4, NULL`,
+		},
+		"nil map": {
+			Template: `{{sql .}}`,
+			Values:   map[string]int(nil),
+			Result:   `NULL`,
+		},
+		"bytes": {
+			Template: `{{sql .}}, {{sql "postgres" .}}, {{sql "sqlite" .}}, {{sql "sqlserver" .}}`,
+			Values:   []byte{0xca, 0xfe},
+			Result:   `X'cafe', '\xcafe', X'cafe', 0xcafe`,
+		},
+		"valuer": {
+			Template: `{{sql .a}}, {{sql .b}}, {{sql .c}}`,
+			Values: map[string]interface{}{
+				"a": sql.NullString{String: "it's", Valid: true},
+				"b": sql.NullInt64{},
+				"c": &sql.NullTime{Time: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), Valid: true},
+			},
+			Result: `'it\'s', NULL, '2021-03-04 00:00:00'`,
+		},
+		"ident": {
+			Template: "{{sqlIdent .}} {{sqlIdent \"postgres\" .}} {{sqlIdent \"sqlite\" .}} {{sqlIdent \"sqlserver\" .}}",
+			Values:   "public.or`der\"s]",
+			Result:   "`public`.`or``der\"s]` \"public\".\"or`der\"\"s]\" \"public\".\"or`der\"\"s]\" [public].[or`der\"s]]]",
+		},
 	}
 
 	for name, test := range tests {
@@ -51,5 +125,56 @@ func TestSQLEscape(t *testing.T) {
 			t.Errorf("%s: '%s' != '%s'", name, res, test.Result)
 		}
 	}
+}
+
+func TestSetSQLDialect(t *testing.T) {
+	if err := SetSQLDialect("oracle"); err == nil {
+		t.Errorf("expected an error for an unknown dialect")
+	}
+	if err := SetSQLDialect("Postgres"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetSQLDialect(MySQL)
+	res, err := Template(`{{sql .}} {{sqlIdent "order"}}`, "it's")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != `'it''s' "order"` {
+		t.Errorf("%s != 'it''s' \"order\"", res)
+	}
+	if _, err := Template(`{{sql "oracle" .}}`, 1); err == nil {
+		t.Errorf("expected an error for an unknown dialect")
+	}
+}
+
+func TestSQLEscapeErrors(t *testing.T) {
+	tests := map[string]interface{}{
+		"NaN":     math.NaN(),
+		"Inf":     []float64{1, math.Inf(1)},
+		"chan":    make(chan int),
+		"complex": complex(1, 2),
+		"json":    map[string]interface{}{"f": func() {}},
+	}
+	for name, value := range tests {
+		if _, err := Template(`{{sql .}}`, value); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
 
+func TestSetSQLTimeFormat(t *testing.T) {
+	if err := SetSQLTimeFormat("oracle", "2006"); err == nil {
+		t.Errorf("expected an error for an unknown dialect")
+	}
+	if err := SetSQLTimeFormat("postgres", "2006-01-02"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetSQLTimeFormat("postgres", "2006-01-02 15:04:05.999999Z07:00")
+	res, err := Template(`{{sql "postgres" .}}`, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != `'2021-03-04'` {
+		t.Errorf("%s != '2021-03-04'", res)
+	}
 }
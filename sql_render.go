@@ -0,0 +1,76 @@
+package gotemplate
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// sqlParams records the values of param during one RenderSQL
+type sqlParams struct {
+	dialect sqlDialect
+	args    []interface{}
+}
+
+// param writes the placeholder of the value and records it, slices expand
+// into a list of placeholders: id in ({{param .ids}}) => id in ($1, $2, $3).
+// Bytes and driver.Valuer slices such as pq.StringArray are one value.
+func (p *sqlParams) param(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	_, valuer := v.(driver.Valuer)
+	if valuer || rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Type().Elem().Kind() == reflect.Uint8 {
+		p.args = append(p.args, v)
+		return p.dialect.placeholder(len(p.args)), nil
+	}
+	if rv.Len() == 0 {
+		return "", fmt.Errorf("param: empty list")
+	}
+	placeholders := make([]string, rv.Len())
+	for i := range placeholders {
+		p.args = append(p.args, rv.Index(i).Interface())
+		placeholders[i] = p.dialect.placeholder(len(p.args))
+	}
+	return strings.Join(placeholders, ", "), nil
+}
+
+// sqlParam is the param func outside of RenderSQL
+func sqlParam(v interface{}) (string, error) {
+	return "", fmt.Errorf("param: only available in RenderSQL")
+}
+
+// inDialect makes sql and sqlIdent default to the dialect of RenderSQL
+func inDialect(dialect string, f func(args ...interface{}) (string, error)) func(args ...interface{}) (string, error) {
+	return func(args ...interface{}) (string, error) {
+		if len(args) == 1 {
+			args = []interface{}{dialect, args[0]}
+		}
+		return f(args...)
+	}
+}
+
+// RenderSQL renders a template as prepared statement of dialect (or the
+// default dialect when empty): param writes the placeholders and the values
+// are returned as args for database/sql, e.g.
+// select * from t where id in ({{param .ids}}) => select * from t where id in (?, ?), [1 2]
+func RenderSQL(tmpl string, data interface{}, dialect string) (string, []interface{}, error) {
+	if dialect == "" {
+		flock.Lock()
+		dialect = defaultSQLDialect
+		flock.Unlock()
+	}
+	d, err := lookupSQLDialect(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	p := &sqlParams{dialect: d}
+	query, err := TemplateWithOptions(tmpl, data, Options{overrides: map[string]interface{}{
+		"param":    p.param,
+		"sql":      inDialect(dialect, sqlEscape),
+		"sqlIdent": inDialect(dialect, sqlIdent),
+	}})
+	if err != nil {
+		return "", nil, err
+	}
+	return query, p.args, nil
+}
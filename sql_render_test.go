@@ -0,0 +1,96 @@
+package gotemplate
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testStringArray is a slice the driver binds as one array value
+type testStringArray []string
+
+func (a testStringArray) Value() (driver.Value, error) {
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+func TestRenderSQL(t *testing.T) {
+	values := map[string]interface{}{
+		"name":  "o'neil",
+		"ids":   []int{1, 2, 3},
+		"data":  []byte("raw"),
+		"table": "order",
+		"tags":  testStringArray{"new", "sale"},
+	}
+	tests := map[string]struct {
+		Template string
+		Dialect  string
+		Query    string
+		Args     []interface{}
+	}{
+		"mysql": {
+			Template: `select * from t where name = {{param .name}} and id in ({{param .ids}})`,
+			Dialect:  "mysql",
+			Query:    `select * from t where name = ? and id in (?, ?, ?)`,
+			Args:     []interface{}{"o'neil", 1, 2, 3},
+		},
+		"default dialect": {
+			Template: `select * from t where name = {{param .name}}`,
+			Query:    `select * from t where name = ?`,
+			Args:     []interface{}{"o'neil"},
+		},
+		"postgres": {
+			Template: `select * from {{sqlIdent .table}} where id in ({{param .ids}}) and name = {{param .name}}`,
+			Dialect:  "postgres",
+			Query:    `select * from "order" where id in ($1, $2, $3) and name = $4`,
+			Args:     []interface{}{1, 2, 3, "o'neil"},
+		},
+		"sqlite": {
+			Template: `insert into t values ({{param .data}}, {{sql .name}})`,
+			Dialect:  "sqlite",
+			Query:    `insert into t values (?, 'o''neil')`,
+			Args:     []interface{}{[]byte("raw")},
+		},
+		"sqlserver": {
+			Template: `select * from {{sqlIdent .table}} where name = {{param .name}} or name = {{param .name}}`,
+			Dialect:  "sqlserver",
+			Query:    `select * from [order] where name = @p1 or name = @p2`,
+			Args:     []interface{}{"o'neil", "o'neil"},
+		},
+		"valuer slice": {
+			Template: `select * from t where tags && {{param .tags}} and id in ({{param .ids}})`,
+			Dialect:  "postgres",
+			Query:    `select * from t where tags && $1 and id in ($2, $3, $4)`,
+			Args:     []interface{}{testStringArray{"new", "sale"}, 1, 2, 3},
+		},
+		"no params": {
+			Template: `select 1`,
+			Dialect:  "postgres",
+			Query:    `select 1`,
+		},
+	}
+
+	for name, test := range tests {
+		query, args, err := RenderSQL(test.Template, values, test.Dialect)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if query != test.Query {
+			t.Errorf("%s: '%s' != '%s'", name, query, test.Query)
+		}
+		if !reflect.DeepEqual(args, test.Args) {
+			t.Errorf("%s: %#v != %#v", name, args, test.Args)
+		}
+	}
+
+	if _, _, err := RenderSQL(`id in ({{param .}})`, []int{}, "mysql"); err == nil {
+		t.Errorf("expected an error for an empty list")
+	}
+	if _, _, err := RenderSQL(`{{param 1}}`, nil, "oracle"); err == nil {
+		t.Errorf("expected an error for an unknown dialect")
+	}
+	if _, err := Template(`{{param 1}}`, nil); err == nil {
+		t.Errorf("expected an error for param outside RenderSQL")
+	}
+}
@@ -3,27 +3,16 @@ package gotemplate
 import (
 	"fmt"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/kennygrant/sanitize"
 )
 
-// slugify
+// urlPath is slugify with the default options
 func urlPath(title string) string {
-	reg, err := regexp.Compile("[^A-Za-z0-9]+")
-	if err != nil {
-		return ""
-	}
-	prettyurl := reg.ReplaceAllString(title, "-")
-	prettyurl = strings.ToLower(strings.Trim(prettyurl, "-"))
-	return prettyurl
-}
-
-func reReplaceAll(pattern, repl, text string) string {
-	re := regexp.MustCompile(pattern)
-	return re.ReplaceAllString(text, repl)
+	slug, _ := slugify(title)
+	return slug
 }
 
 func empty(a interface{}) interface{} {
@@ -88,8 +77,9 @@ func sanitise(str string) string {
 func limit(data interface{}, length int) interface{} {
 	switch reflect.ValueOf(data).Kind() {
 	case reflect.String:
-		if len(data.(string)) > length {
-			return data.(string)[:length]
+		s := reflect.ValueOf(data).String()
+		if stringWidth(s) > length {
+			return cutWidth(s, length)
 		}
 		return data
 
@@ -104,7 +94,7 @@ func limit(data interface{}, length int) interface{} {
 func fixlen(length int, data interface{}) interface{} {
 	switch reflect.ValueOf(data).Kind() {
 	case reflect.String:
-		return fmt.Sprintf(fmt.Sprintf("%%-%d.%ds", length, length), data)
+		return fixWidth(reflect.ValueOf(data).String(), length, "left")
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return fmt.Sprintf(fmt.Sprintf("%%-%d.%dd", length, length), data)
 	case reflect.Float32, reflect.Float64:
@@ -116,7 +106,7 @@ func fixlen(length int, data interface{}) interface{} {
 func fixlenright(length int, data interface{}) interface{} {
 	switch reflect.ValueOf(data).Kind() {
 	case reflect.String:
-		return fmt.Sprintf(fmt.Sprintf("%%%d.%ds", length, length), data)
+		return fixWidth(reflect.ValueOf(data).String(), length, "right")
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return fmt.Sprintf(fmt.Sprintf("%%%d.%dd", length, length), data)
 	case reflect.Float32, reflect.Float64:
@@ -166,7 +156,3 @@ func hasPrefix(substr string, str string) bool {
 func hasSuffix(substr string, str string) bool {
 	return strings.HasSuffix(str, substr)
 }
-
-func regReplaceAll(replaceRegex, input string) string {
-	return regexp.MustCompile(replaceRegex).ReplaceAllString(input, "")
-}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/shoobyban/mxj"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cast"
 )
 
@@ -16,44 +17,46 @@ func jsonEncode(v interface{}) (string, error) {
 	return string(b), err
 }
 
+// xmlEncode writes strings as CDATA, floats with 4 decimals, lists indented
+// in items/item elements (or the given item and root tags) and maps as
+// elements, see xmlWrite for more control
 func xmlEncode(v interface{}, args ...string) (string, error) {
-	if str, ok := v.(string); ok {
-		return "<![CDATA[" + str + "]]>", nil
+	switch val := v.(type) {
+	case string:
+		return "<![CDATA[" + val + "]]>", nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', 4, 64), nil
 	}
-	if fl, ok := v.(float64); ok {
-		return strconv.FormatFloat(fl, 'f', 4, 64), nil
-	}
-	if fl, ok := v.(int64); ok {
-		return strconv.FormatInt(fl, 64), nil
-	}
-	if fl, ok := v.(int); ok {
-		return strconv.Itoa(fl), nil
-	}
-	if arr, ok := v.([]interface{}); ok {
-		itemtag := "item"
-		roottag := "items"
+	opts := defaultXMLOptions()
+	if _, ok := toSlice(v); ok {
+		opts.root, opts.indent = "items", "  "
 		if len(args) > 0 {
-			itemtag = args[0]
+			opts.item = args[0]
 		}
 		if len(args) > 1 {
-			roottag = args[1]
+			opts.root = args[1]
 		}
-		mv := mxj.Map(map[string]interface{}{itemtag: arr})
-		mxj.XMLEscapeChars(true)
-		b, err := mv.XmlIndent("", "  ", roottag)
-		return string(b), err
-	}
-	mv := mxj.Map(v.(map[string]interface{}))
-	mxj.XMLEscapeChars(true)
-	b, err := mv.Xml()
-	return string(b), err
+	}
+	w := &xmlWriter{opts: opts}
+	res, err := w.write(v)
+	if err != nil {
+		return "", fmt.Errorf("xml_encode: %v", err)
+	}
+	return res, nil
 }
 
 func xmlArray(v interface{}, roottag, itemtag string) (string, error) {
-	mv := mxj.Map(map[string]interface{}{itemtag: v.([]interface{})})
-	mxj.XMLEscapeChars(true)
-	b, err := mv.XmlIndent("", "  ", roottag)
-	return "<?xml version=\"1.0\"?>\n" + string(b), err
+	if _, ok := toSlice(v); !ok {
+		return "", fmt.Errorf("xml_array: expected a list, got %T", v)
+	}
+	opts := defaultXMLOptions()
+	opts.root, opts.item, opts.indent, opts.declaration = roottag, itemtag, "  ", true
+	w := &xmlWriter{opts: opts}
+	res, err := w.write(v)
+	if err != nil {
+		return "", fmt.Errorf("xml_array: %v", err)
+	}
+	return res, nil
 }
 
 func decode(s, format string) (interface{}, error) {
@@ -91,82 +94,155 @@ func asJSON(s interface{}) string {
 	return string(jsonBytes)
 }
 
-func pathValue(keys []string, s interface{}, f string) (v interface{}) {
-	var key string
-	var nextkeys []string
+// pathValues walks the dotted path keys through s and returns every value
+// found. Keys index maps, integer keys (negative ones from the end) index
+// slices, other keys on a slice apply to each element, and [predicate] keys
+// keep the elements (or the map itself) matching the predicate. fanout
+// reports whether the path went through more than one branch; inside such
+// branches missing keys and indices drop the branch instead of failing.
+func pathValues(keys []string, s interface{}, fanout bool) ([]interface{}, bool, error) {
 	if len(keys) == 0 {
-		if f == "" {
-			return s
-		}
-		key = ""
-		nextkeys = keys
-	} else {
-		key = keys[0]
-		nextkeys = keys[1:]
+		return []interface{}{s}, fanout, nil
 	}
-	filter := ""
-	var (
-		i  int64
-		ok bool
-	)
-	var err error
-
-	if key != "" && key[:1] == "[" && key[len(key)-1:] == "]" {
-		key, filter = "", key[1:len(key)-1]
+	key, nextkeys := keys[0], keys[1:]
+	if key == "" {
+		return pathValues(nextkeys, s, fanout)
 	}
 
-	switch s.(type) {
-	case map[string]interface{}:
-		if key == "" {
-			m := map[string]interface{}{}
-			found := true
-			if f != "" {
-				found = false
-				fparts := strings.Split(f, "=")
-				for k, item := range s.(map[string]interface{}) {
-					if k == fparts[0] && item == fparts[1] {
-						found = true
-					}
-				}
+	if key[:1] == "[" && key[len(key)-1:] == "]" {
+		pred, err := parsePredicate(key[1 : len(key)-1])
+		if err != nil {
+			return nil, fanout, err
+		}
+		items, ok := toSlice(s)
+		if !ok {
+			items = []interface{}{s}
+		}
+		a := []interface{}{}
+		for _, item := range items {
+			found, err := pred.match(item)
+			if err != nil {
+				return nil, fanout, err
 			}
-			if found {
-				for k, item := range s.(map[string]interface{}) {
-					m[k] = pathValue(nextkeys, item, filter)
-				}
+			if !found {
+				continue
 			}
-			if len(m) > 0 {
-				v = m
+			pv, _, err := pathValues(nextkeys, item, true)
+			if err != nil {
+				return nil, fanout, err
 			}
-		} else if v, ok = s.(map[string]interface{})[key]; !ok {
-			err = fmt.Errorf("Key not present. [Key:%s]", key)
+			a = append(a, pv...)
 		}
-	case []interface{}:
-		array := s.([]interface{})
-		a := []interface{}{}
-		if f != "" {
-			return a
-		}
-		if key == "" {
+		return a, true, nil
+	}
+
+	if array, ok := toSlice(s); ok {
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			a := []interface{}{}
 			for _, item := range array {
-				pv := pathValue(nextkeys, item, filter)
-				if pv != nil {
-					a = append(a, pv)
+				pv, _, err := pathValues(keys, item, true)
+				if err != nil {
+					return nil, fanout, err
 				}
+				a = append(a, pv...)
 			}
-			if len(a) == 1 {
-				v = a[0]
-			} else if len(a) > 0 {
-				v = a
+			return a, true, nil
+		}
+		if i < 0 {
+			i += len(array)
+		}
+		if i < 0 || i >= len(array) {
+			if fanout {
+				return nil, fanout, nil
 			}
-		} else if i, err = strconv.ParseInt(key, 10, 64); err == nil {
-			if int(i) < len(array) {
-				v = array[i]
-			} else {
-				err = fmt.Errorf("Index out of bounds. [Index:%d] [Array:%v]", i, array)
+			return nil, fanout, fmt.Errorf("Index out of bounds. [Index:%s] [Array:%v]", key, array)
+		}
+		return pathValues(nextkeys, array[i], fanout)
+	}
+
+	if m, ok := toMap(s); ok {
+		v, ok := m[key]
+		if !ok {
+			if fanout {
+				return nil, fanout, nil
 			}
+			return nil, fanout, fmt.Errorf("Key not present. [Key:%s]", key)
 		}
+		return pathValues(nextkeys, v, fanout)
 	}
-	return pathValue(nextkeys, v, "")
+
+	if fanout || s == nil {
+		return nil, fanout, nil
+	}
+	return nil, fanout, fmt.Errorf("Can't look up key in %T. [Key:%s]", s, key)
+}
+
+// splitPath splits a filter path on dots outside of [predicates] and quotes
+func splitPath(p string) []string {
+	keys := []string{}
+	depth := 0
+	var quote rune
+	start := 0
+	for i, r := range p {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case depth > 0 && (r == '\'' || r == '"'):
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case r == '.' && depth == 0:
+			keys = append(keys, p[start:i])
+			start = i + 1
+		}
+	}
+	return append(keys, p[start:])
+}
+
+// toMap returns maps with string keys (mxj.Map, csv rows, ...) as
+// map[string]interface{}
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case mxj.Map:
+		return m, true
+	case nil:
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		m[k.String()] = rv.MapIndex(k).Interface()
+	}
+	return m, true
+}
+
+// toSlice returns any slice or array (except []byte) as []interface{}
+func toSlice(v interface{}) ([]interface{}, bool) {
+	switch a := v.(type) {
+	case []interface{}:
+		return a, true
+	case []byte, nil:
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	a := make([]interface{}, rv.Len())
+	for i := range a {
+		a[i] = rv.Index(i).Interface()
+	}
+	return a, true
 }
 
 func explode(s, sep string) []string {
@@ -245,10 +321,13 @@ func mkSlice(args ...interface{}) []interface{} {
 }
 
 func decimalFmt(format string, number interface{}) string {
-	num := emptyStr(number)
-	f, _ := strconv.ParseFloat(num, 64)
+	d, err := toDecimal(number)
+	if err != nil {
+		d = decimal.Zero
+	}
 	i := strings.Split(format, ",")
-	s := fmt.Sprintf(fmt.Sprintf("%%%s.%sf", i[0], i[1]), f)
+	places, _ := strconv.Atoi(i[1])
+	s := fmt.Sprintf(fmt.Sprintf("%%%ss", i[0]), d.StringFixed(int32(places)))
 	if i[1] != "0" {
 		s = strings.TrimRight(s, "0")
 		if s[len(s)-1:] == "." {
@@ -262,8 +341,39 @@ func last(x int, a interface{}) bool {
 	return x == reflect.ValueOf(a).Len()-1
 }
 
-func filterPath(s interface{}, p string) interface{} {
-	return pathValue(strings.Split(p, "."), s, "")
+// filterPath returns the value at path p, e.g. "data.[iso=GB].name".
+// Multiple matches are returned as []interface{}, a single match as the value
+// itself and no match as nil.
+func filterPath(s interface{}, p string) (interface{}, error) {
+	res, _, err := pathValues(splitPath(p), s, false)
+	if err != nil {
+		return nil, err
+	}
+	switch len(res) {
+	case 0:
+		return nil, nil
+	case 1:
+		return res[0], nil
+	}
+	return res, nil
+}
+
+// filterAll is filterPath always returning a slice, so the result can be
+// ranged over whatever the number of matches is
+func filterAll(s interface{}, p string) ([]interface{}, error) {
+	res, fanout, err := pathValues(splitPath(p), s, false)
+	if err != nil {
+		return nil, err
+	}
+	if !fanout && len(res) == 1 {
+		if a, ok := toSlice(res[0]); ok {
+			return a, nil
+		}
+		if res[0] == nil {
+			return []interface{}{}, nil
+		}
+	}
+	return res, nil
 }
 
 func toAbs(float float64) float64 {
@@ -2,7 +2,6 @@ package gotemplate
 
 import (
 	"bytes"
-	"regexp"
 	"strings"
 	"sync"
 	"text/template"
@@ -11,73 +10,182 @@ import (
 var flock = sync.Mutex{}
 
 var fmap = template.FuncMap{
-	"add":             add,
-	"concat":          concat,   // concat "a" "b" => "ab"
-	"contains":        contains, // contains "a" "abc" => true
-	"createMap":       createMap,
-	"date":            dateFmt, // "2017-03-31 19:59:11" |  date "06.01.02" => "17.03.31"
-	"dateFrom":        dateFmtLayout,
-	"datetime":        datetime,
-	"decimal":         decimalFmt, // 3.1415 decimal 6,2 => 3.14
-	"div":             divide,
-	"elseifthen":      notconditional, // elseifthen "a" "b" => b, elseifthen "" "b" => ""
-	"empty":           empty,          // empty [] => "", ["bah"] => "bah"
-	"escape":          escape,
-	"explode":         explode,
-	"filter":          filterPath,
-	"fixlen":          fixlen,
-	"fixlenr":         fixlenright,
-	"float":           tofloat, // float "0123.234" => 123.234
-	"formatUKDate":    formatUKDate,
-	"hasPrefix":       hasPrefix,   // hasPrefix "a" "ab" => true
-	"hasSuffix":       hasSuffix,   // hasSuffix "a" "ba" => true
-	"ifthen":          conditional, // ifthen "a" "b" => a, ifthen "" "b" => b
-	"in_array":        inArray,
-	"int":             toint, // int "0123" => 123
-	"isset":           isSet,
-	"item":            item, // item "a:b" ":" 0 => a
-	"json_decode":     jsonDecode,
-	"json_encode":     jsonEncode,
-	"json_escape":     jsonEscape,
-	"json":            asJSON,
-	"last":            last,
-	"limit":           limit,
-	"lower":           strings.ToLower,
-	"mapto":           mapto, // mapto "a" "a:True|b:False" "|:" => True
-	"match":           regexp.MatchString,
-	"regexpReplace":   regReplaceAll, // regexpReplace "[^a-zA-Z0-9]" "!as.d?f12∂3" => "asdf123"
-	"md5":             md5hash,
-	"mkSlice":         mkSlice,
-	"mul":             multiply,
-	"nanotimestamp":   nanotimestamp,
-	"replace":         replace,
-	"reReplaceAll":    reReplaceAll,
-	"sanitise":        sanitise,
-	"sanitize":        sanitise,
-	"seq":             seq,
-	"setItem":         setItem,
-	"sql":             sqlEscape,
-	"sub":             subtract,
-	"timeformat":      timeFormat,
-	"timeformatminus": timeFormatMinus,
-	"timestamp":       timestamp,
-	"title":           strings.Title,
-	"toAbs":           toAbs,
-	"tojson":          jsonDecode, // backward compatibility
-	"toLower":         strings.ToLower,
-	"toUpper":         strings.ToUpper,
-	"ukdate":          ukdate,
-	"ukdatetime":      ukdatetime,
-	"unique":          unique,
-	"unixtimestamp":   unixtimestamp,
-	"upper":           strings.ToUpper,
-	"url_path":        urlPath, // SEO, Slugify
-	"urldecode":       urldecode,
-	"urlencode":       urlencode,
-	"xml_array":       xmlArray,
-	"xml_decode":      xmlDecode,
-	"xml_encode":      xmlEncode,
-	"xml":             xmlEncode,
+	"add":              add,
+	"avg":              avg,
+	"base32":           base32Encode,
+	"base32Decode":     base32Decode,
+	"base64":           base64Encode,
+	"base64Decode":     base64Decode,
+	"base64url":        base64urlEncode,
+	"base64urlDecode":  base64urlDecode,
+	"camelCase":        camelCase,
+	"ceil":             ceil,
+	"center":           center, // .title | center 40 "="
+	"chunk":            chunk,  // chunk (mkSlice 1 2 3) 2 => [[1 2] [3]]
+	"clamp":            clamp,  // clamp 0 100 .percent
+	"clone":            Clone,
+	"concat":           concat,    // concat "a" "b" => "ab"
+	"contains":         contains,  // contains "a" "abc" => true
+	"convertTZ":        convertTZ, // convertTZ "15:04" "UTC" "Europe/Paris" .date
+	"count":            count,
+	"counter":          counter, // counter "segment" => 1, 2, 3...
+	"crc32":            crc32hash,
+	"createMap":        createMap,
+	"date":             dateFmt,  // "2017-03-31 19:59:11" |  date "06.01.02" => "17.03.31"
+	"dateAdd":          dateAdd,  // .date | dateAdd 3 "businessdays"
+	"dateDiff":         dateDiff, // dateDiff "days" .ordered .delivered
+	"dateFrom":         dateFmtLayout,
+	"datetime":         datetime,
+	"dateTZ":           dateTZ,     // .date | dateTZ "02/01/06 15:04" "Europe/London"
+	"dec":              dec,        // dec "0.1" | add (dec "0.2") => 0.3
+	"decimal":          decimalFmt, // 3.1415 decimal 6,2 => 3.14
+	"deepMerge":        deepMerge,  // deepMerge $defaults $m "union"
+	"deletePath":       deletePath,
+	"div":              divide,
+	"elseifthen":       notconditional, // elseifthen "a" "b" => b, elseifthen "" "b" => ""
+	"empty":            empty,          // empty [] => "", ["bah"] => "bah"
+	"endOf":            endOf,          // endOf "month" .date
+	"escape":           escape,
+	"explode":          explode,
+	"filter":           filterPath, // filter . "data.[iso in (GB,IE) and pop>1000].name" => [Great Britain Ireland]
+	"filterAll":        filterAll,  // filterAll . "data.[iso=GB]" => [map[iso:GB name:Great Britain]]
+	"first":            first,
+	"fixlen":           fixlen,
+	"fixlenr":          fixlenright,
+	"flatten":          flatten,
+	"float":            tofloat, // float "0123.234" => 123.234
+	"floor":            floor,
+	"formatCurrency":   FormatCurrency, // formatCurrency "en-GB" "GBP" 1234.5 => £1,234.50
+	"formatIBAN":       formatIBAN,     // formatIBAN "gb82west12345698765432" => GB82 WEST 1234 5698 7654 32
+	"formatNumber":     FormatNumber,   // formatNumber "de-DE" 2 1234.5 => 1.234,50
+	"formatPercent":    FormatPercent,  // formatPercent "en-GB" 1 0.125 => 12.5%
+	"formatUKDate":     formatUKDate,
+	"fromExcel":        fromExcel, // (fromExcel 42825.5).Format "02/01/2006" => 31/03/2017
+	"fromUnix":         fromUnix,  // (fromUnix .ts).Format "2006-01-02"
+	"fromUnixMicro":    fromUnixMicro,
+	"fromUnixMilli":    fromUnixMilli,
+	"groupBy":          groupBy,       // groupBy .items "category" => map[cat:[...]]
+	"gs1CheckDigit":    gs1CheckDigit, // gs1CheckDigit "400638133393" => 1
+	"gs1Complete":      gs1Complete,
+	"hasPrefix":        hasPrefix, // hasPrefix "a" "ab" => true
+	"hasSuffix":        hasSuffix, // hasSuffix "a" "ba" => true
+	"hex":              hexEncode,
+	"hexDecode":        hexDecode,
+	"hmac":             hmacHash,         // hmac "sha256" .secret .payload
+	"humanizeDuration": humanizeDuration, // humanizeDuration "80m" => 1h 20m
+	"humanizeTime":     humanizeTime,     // humanizeTime .created => 3 days ago
+	"ifthen":           conditional,      // ifthen "a" "b" => a, ifthen "" "b" => b
+	"in_array":         inArray,
+	"int":              toint, // int "0123" => 123
+	"inTZ":             inTZ,  // (inTZ "Europe/London" .created).Format "15:04"
+	"isbn13":           isbn13,
+	"isBusinessDay":    isBusinessDay,
+	"isGS1":            isGS1, // EAN-8, UPC-A, EAN-13, GTIN-14, SSCC
+	"isIBAN":           isIBAN,
+	"isISBN":           isISBN,
+	"isLuhn":           isLuhn,
+	"isoWeek":          isoWeek,
+	"isset":            isSet,
+	"isUKVAT":          isUKVAT,
+	"item":             item, // item "a:b" ":" 0 => a
+	"json_decode":      jsonDecode,
+	"json_encode":      jsonEncode,
+	"json_escape":      jsonEscape,
+	"json":             asJSON,
+	"jsonpath":         JSONPath, // jsonpath . "$.items[?(@.qty > 1)].sku" => [A B]
+	"jq":               JQ,       // jq . ".items[].sku" => [A B C]
+	"jqFirst":          JQFirst,  // jqFirst . "[.items[].qty] | add" => 5
+	"kebabCase":        kebabCase,
+	"keys":             keys,
+	"last":             last,
+	"limit":            limit,
+	"lower":            strings.ToLower,
+	"luhnCheckDigit":   luhnCheckDigit,
+	"mapto":            mapto, // mapto "a" "a:True|b:False" "|:" => True
+	"match":            match,
+	"max":              maxOf,
+	"md5legacy":        md5legacy, // hashes the %#v representation
+	"min":              minOf,
+	"mod":              modulo, // mod 3 10 => 1
+	"now":              now,    // (now).Format "2006"
+	"omit":             omit,
+	"padLeft":          padLeft, // .sku | padLeft 8 "0"
+	"padRight":         padRight,
+	"param":            sqlParam,      // placeholder of RenderSQL
+	"parseDate":        parseDate,     // parseDate "31/03/2017" "n/a"
+	"parseDuration":    parseDuration, // parseDuration "1w 2d"
+	"pascalCase":       pascalCase,
+	"pick":             pick,         // pick $m "sku" "qty"
+	"pow":              power,        // pow 2 10 => 100
+	"randomInt":        randomInt,    // randomInt 1 6
+	"randomString":     randomString, // randomString 8 "digits"
+	"reFind":           reFind,
+	"reFindAll":        reFindAll,     // reFindAll "\\d+" "a1b22" => [1 22]
+	"regexpReplace":    regReplaceAll, // regexpReplace "[^a-zA-Z0-9]" "!as.d?f12∂3" => "asdf123"
+	"md5":              md5hash,       // md5 "abc" => 900150983cd24fb0d6963f7d28e17f72
+	"mkSlice":          mkSlice,
+	"mul":              multiply,
+	"nanotimestamp":    nanotimestamp,
+	"pluck":            pluck,      // pluck .items "sku" => [A B]
+	"renameKeys":       renameKeys, // renameKeys $m "old" "new"
+	"replace":          replace,
+	"reReplaceAll":     reReplaceAll,
+	"reSplit":          reSplit,
+	"rest":             rest,
+	"reSubmatch":       reSubmatch, // (reSubmatch "(?P<year>\\d{4})" .date).year
+	"reverse":          reverse,
+	"round":            round, // round 2 "2.345" "half-even" => 2.34
+	"sanitise":         sanitise,
+	"sanitize":         sanitise,
+	"screamingSnake":   screamingSnake,
+	"seq":              seq,
+	"setItem":          setItem,
+	"setPath":          setPath, // setPath $m "a.b.0.c" "v" => map[a:map[b:[map[c:v]]]]
+	"sha1":             sha1hash,
+	"sha256":           sha256hash,
+	"sha512":           sha512hash,
+	"slugify":          slugify,   // slugify "lang=de" "Müller & Söhne" => mueller-soehne
+	"snakeCase":        snakeCase, // "OrderLineItem" | snakeCase => order_line_item
+	"sortBy":           sortBy,    // sortBy .items "price desc" "name"
+	"sql":              sqlEscape,
+	"sqlIdent":         sqlIdent, // quotes identifiers for the sql dialect
+	"startOf":          startOf,  // startOf "week" .date
+	"sub":              subtract,
+	"sum":              sum,          // sum .items "qty" => 6
+	"t":                translateMsg, // t "cart.items" "count" 3 => 3 items
+	"timeformat":       timeFormat,
+	"timeformatminus":  timeFormatMinus,
+	"timestamp":        timestamp,
+	"title":            strings.Title,
+	"titleCase":        titleCase, // titleCase "nl" "ijsselmeer" => IJsselmeer
+	"toAbs":            toAbs,
+	"toFixed":          toFixed,    // toFixed 2 "12.3" => 12.30
+	"tojson":           jsonDecode, // backward compatibility
+	"toLower":          strings.ToLower,
+	"toUpper":          strings.ToUpper,
+	"truncate":         truncate, // .name | truncate 20 "words"
+	"ukdate":           ukdate,
+	"ukdatetime":       ukdatetime,
+	"unique":           unique,
+	"unixmicro":        unixmicro,
+	"unixmilli":        unixmilli,
+	"unixtimestamp":    unixtimestamp,
+	"upper":            strings.ToUpper,
+	"url_path":         urlPath, // SEO, Slugify
+	"urldecode":        urldecode,
+	"urlencode":        urlencode,
+	"uuid":             uuid,   // uuid, uuid "v7"
+	"uuidv5":           uuidv5, // uuidv5 "url" "https://example.com/order/1"
+	"values":           values,
+	"where":            where,    // where .items "qty" ">" 2
+	"wordwrap":         wordwrap, // .address | wordwrap 30 "  "
+	"xml_array":        xmlArray,
+	"xml_decode":       xmlDecode,
+	"xml_encode":       xmlEncode,
+	"xml":              xmlEncode,
+	"xmlWrite":         xmlWrite, // XML with cdata, namespace, indent... options
+	"xpath":            XPath,    // xpath . "//book[@lang='en'][1]/title" => [Title]
+	"xpathOne":         XPathOne, // xpathOne . "count(//book)" => 2
 }
 
 // RegisterFunc registers a new template func to the template parser
@@ -89,10 +197,9 @@ func RegisterFunc(key string, templatefunc interface{}) {
 
 // GetFuncs will return all usable template funcs as string slice
 func GetFuncs() []string {
-	flock.Lock()
-	defer flock.Unlock()
-	keys := make([]string, 0, len(fmap))
-	for k := range fmap {
+	funcs := Options{}.funcs()
+	keys := make([]string, 0, len(funcs))
+	for k := range funcs {
 		keys = append(keys, k)
 	}
 	return keys
@@ -109,7 +216,7 @@ func MustTemplate(str string, data interface{}) string {
 
 // TemplateDelim parses string with custom delimiters as Go template, using data as scope
 func TemplateDelim(str string, data interface{}, begin, end string) (string, error) {
-	tmpl, err := template.New("test").Funcs(fmap).Delims(begin, end).Parse(str)
+	tmpl, err := template.New("test").Funcs(Options{}.funcs()).Delims(begin, end).Parse(str)
 	if err == nil {
 		var doc bytes.Buffer
 		err = tmpl.Execute(&doc, data)
@@ -121,9 +228,47 @@ func TemplateDelim(str string, data interface{}, begin, end string) (string, err
 	return "", err
 }
 
+// Options are per-render settings of TemplateWithOptions
+type Options struct {
+	// Locale is used instead of the default locale by t, the humanize funcs
+	// and the format funcs when they get an empty locale
+	Locale string
+
+	// overrides replace template funcs for one render, e.g. param of RenderSQL
+	overrides template.FuncMap
+}
+
+// funcs returns the template funcs of a render with the per-render ones of
+// the options
+func (o Options) funcs() template.FuncMap {
+	flock.Lock()
+	funcs := make(template.FuncMap, len(fmap))
+	for k, v := range fmap {
+		funcs[k] = v
+	}
+	flock.Unlock()
+	if o.Locale != "" {
+		for k, v := range localeFuncs(o.Locale) {
+			funcs[k] = v
+		}
+	}
+	for k, v := range o.overrides {
+		funcs[k] = v
+	}
+	// runs its templates with the funcs of this render
+	funcs["reReplaceFunc"] = reReplaceFuncWith(funcs)
+	return funcs
+}
+
 // Template parses string as Go template, using data as scope
 func Template(str string, data interface{}) (string, error) {
-	tmpl, err := template.New("test").Funcs(fmap).Parse(str)
+	return TemplateWithOptions(str, data, Options{})
+}
+
+// TemplateWithOptions parses string as Go template, using data as scope and
+// the per-render options, e.g. the locale of the customer
+func TemplateWithOptions(str string, data interface{}, opts Options) (string, error) {
+	tmpl, err := template.New("test").Funcs(opts.funcs()).Parse(str)
 	if err == nil {
 		var doc bytes.Buffer
 		err = tmpl.Execute(&doc, data)
@@ -63,8 +63,8 @@ func TestTemplate(t *testing.T) {
  cat`},
 			Result: `dog \"fish\"\n cat`,
 		},
-		"md5": {
-			Template: `{{md5 .A}}`,
+		"md5legacy": {
+			Template: `{{md5legacy .A}}`,
 			Values:   map[string]interface{}{"A": []interface{}{}, "B": 1},
 			Result:   `456a37d61262ccf952ee9768cbe32d94`,
 		},
@@ -269,6 +269,26 @@ func TestTemplate(t *testing.T) {
 			},
 			Result: `1`,
 		},
+		"filter operators": {
+			Template: `{{ filter .countries "data.[pop>60 and iso!=FR].name" }}|{{ filter .countries "data.[iso in (GB, 'IE') or name~=^Fr].iso" }}|{{ filter .countries "data.-1.iso" }}`,
+			Values:   testFilterCountries,
+			Result:   `United Kingdom|[GB FR IE]|IE`,
+		},
+		"filter numeric": {
+			Template: `{{ filter .countries "data.[pop<=5.2].name" }}|{{ filter .countries "data.[pop=67].iso" }}`,
+			Values:   testFilterCountries,
+			Result:   `Ireland|GB`,
+		},
+		"filterAll": {
+			Template: `{{ range filterAll .countries "data.[iso=GB]" }}{{ .name }};{{ end }}|{{ len (filterAll .countries "data.[iso=XX]") }}|{{ len (filterAll .countries "data") }}`,
+			Values:   testFilterCountries,
+			Result:   `United Kingdom;|0|3`,
+		},
+		"filter single map": {
+			Template: `{{ $c := filter (xml_decode .xml) "countries.country.[iso=GB]" }}{{ $c.name }}`,
+			Values:   map[string]interface{}{"xml": `<countries><country><iso>GB</iso><name>United Kingdom</name></country></countries>`},
+			Result:   `United Kingdom`,
+		},
 	}
 	for name, test := range tests {
 		res, err := Template(test.Template, test.Values)
@@ -280,3 +300,27 @@ func TestTemplate(t *testing.T) {
 		}
 	}
 }
+
+var testFilterCountries = map[string]interface{}{
+	"countries": map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"iso": "GB", "name": "United Kingdom", "pop": 67},
+			map[string]interface{}{"iso": "FR", "name": "France", "pop": "68.1"},
+			map[string]interface{}{"iso": "IE", "name": "Ireland", "pop": 5.1},
+		},
+	},
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing key":   "data.0.capital",
+		"out of bounds": "data.5",
+		"bad regexp":    "data.[name~='(']",
+		"bad operator":  "data.[name ! x]",
+	}
+	for name, path := range tests {
+		if _, err := filterPath(testFilterCountries["countries"], path); err == nil {
+			t.Errorf("%s: expected error for %s", name, path)
+		}
+	}
+}
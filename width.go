@@ -0,0 +1,218 @@
+package gotemplate
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+var eastAsianWidth = false
+
+// SetEastAsianWidth makes the width funcs (fixlen, limit, truncate, pad...)
+// measure display width, counting wide CJK characters as two columns and
+// combining marks as none, instead of counting characters
+func SetEastAsianWidth(on bool) {
+	flock.Lock()
+	defer flock.Unlock()
+	eastAsianWidth = on
+}
+
+func getEastAsianWidth() bool {
+	flock.Lock()
+	defer flock.Unlock()
+	return eastAsianWidth
+}
+
+func runeWidth(r rune, eastAsian bool) int {
+	if !eastAsian {
+		return 1
+	}
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// stringWidth is the number of characters or the display width of s
+func stringWidth(s string) int {
+	ea := getEastAsianWidth()
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r, ea)
+	}
+	return w
+}
+
+// cutWidth returns the longest prefix of s that fits in w columns
+func cutWidth(s string, w int) string {
+	ea := getEastAsianWidth()
+	used := 0
+	for i, r := range s {
+		rw := runeWidth(r, ea)
+		if used+rw > w {
+			return s[:i]
+		}
+		used += rw
+	}
+	return s
+}
+
+// padWidth pads s with pad to w columns, align is "left" (pad on the
+// right), "right" or "center"
+func padWidth(s string, w int, pad string, align string) string {
+	if stringWidth(pad) == 0 {
+		pad = " "
+	}
+	missing := w - stringWidth(s)
+	if missing <= 0 {
+		return s
+	}
+	fill := func(n int) string {
+		p := strings.Repeat(pad, n/stringWidth(pad)+1)
+		return cutWidth(p, n) + strings.Repeat(" ", n-stringWidth(cutWidth(p, n)))
+	}
+	switch align {
+	case "right":
+		return fill(missing) + s
+	case "center":
+		return fill(missing/2) + s + fill(missing-missing/2)
+	}
+	return s + fill(missing)
+}
+
+// fixWidth cuts or pads s to exactly w columns
+func fixWidth(s string, w int, align string) string {
+	s = cutWidth(s, w)
+	// a wide character may leave a column to fill
+	return padWidth(s, w, " ", align)
+}
+
+// stringArgs formats the template args as strings, so numbers can be piped
+func stringArgs(args []interface{}) []string {
+	res := make([]string, len(args))
+	for i, a := range args {
+		res[i] = fmt.Sprint(a)
+	}
+	return res
+}
+
+// padArgs splits the optional pad string from the piped value
+func padArgs(fname string, args []interface{}) (string, string, error) {
+	s := stringArgs(args)
+	switch len(s) {
+	case 1:
+		return " ", s[0], nil
+	case 2:
+		return s[0], s[1], nil
+	}
+	return "", "", fmt.Errorf("%s: expected length [pad] string", fname)
+}
+
+// padLeft pads to length on the left: .sku | padLeft 8 "0" => 00012345
+func padLeft(length int, args ...interface{}) (string, error) {
+	pad, s, err := padArgs("padLeft", args)
+	if err != nil {
+		return "", err
+	}
+	return padWidth(s, length, pad, "right"), nil
+}
+
+// padRight pads to length on the right: .name | padRight 20 "."
+func padRight(length int, args ...interface{}) (string, error) {
+	pad, s, err := padArgs("padRight", args)
+	if err != nil {
+		return "", err
+	}
+	return padWidth(s, length, pad, "left"), nil
+}
+
+// center pads on both sides to length: .title | center 40 "="
+func center(length int, args ...interface{}) (string, error) {
+	pad, s, err := padArgs("center", args)
+	if err != nil {
+		return "", err
+	}
+	return padWidth(s, length, pad, "center"), nil
+}
+
+// truncate shortens s to length including the ellipsis (default "…"),
+// options: "words" cuts at a word boundary, "ellipsis=..." sets the ellipsis:
+// .name | truncate 20 "words" "ellipsis=..."
+func truncate(length int, a ...interface{}) (string, error) {
+	args := stringArgs(a)
+	if len(args) == 0 {
+		return "", fmt.Errorf("truncate: expected length [options] string")
+	}
+	s := args[len(args)-1]
+	ellipsis, words := "…", false
+	for _, opt := range args[:len(args)-1] {
+		switch {
+		case opt == "words":
+			words = true
+		case strings.HasPrefix(opt, "ellipsis="):
+			ellipsis = strings.TrimPrefix(opt, "ellipsis=")
+		default:
+			return "", fmt.Errorf("truncate: unknown option %s", opt)
+		}
+	}
+	if stringWidth(s) <= length {
+		return s, nil
+	}
+	room := length - stringWidth(ellipsis)
+	if room <= 0 {
+		return cutWidth(ellipsis, length), nil
+	}
+	cut := cutWidth(s, room)
+	if words {
+		rest := []rune(s[len(cut):])
+		if len(rest) > 0 && !unicode.IsSpace(rest[0]) {
+			if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+				cut = cut[:i]
+			}
+		}
+		cut = strings.TrimRightFunc(cut, func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsPunct(r)
+		})
+	}
+	return cut + ellipsis, nil
+}
+
+// wordwrap wraps text at width columns, indenting the continuation lines
+// with the optional hanging indent; words longer than width get a line of
+// their own: .address | wordwrap 30 "    "
+func wordwrap(w int, a ...interface{}) (string, error) {
+	args := stringArgs(a)
+	var indent, text string
+	switch len(args) {
+	case 1:
+		text = args[0]
+	case 2:
+		indent, text = args[0], args[1]
+	default:
+		return "", fmt.Errorf("wordwrap: expected width [indent] string")
+	}
+	lines := []string{}
+	for _, para := range strings.Split(text, "\n") {
+		line, prefix := "", ""
+		for _, word := range strings.Fields(para) {
+			switch {
+			case line == "":
+				line = prefix + word
+			case stringWidth(line)+1+stringWidth(word) <= w:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				prefix = indent
+				line = prefix + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,48 @@
+package gotemplate
+
+import (
+	"testing"
+)
+
+func TestWidth(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"fixlen runes": {
+			Template: `'{{ "Crème brûlée" | fixlen 8 }}'|'{{ "Öl" | fixlenr 4 }}'|'{{ limit "Ärger" 2 }}'`,
+			Result:   "'Crème br'|'  Öl'|'Är'",
+		},
+		"pad": {
+			Template: `{{ .sku | padLeft 8 "0" }}|{{ "Tee" | padRight 6 "." }}|{{ "Ü" | center 5 "*" }}|{{ "abcdef" | padLeft 3 }}`,
+			Values:   map[string]interface{}{"sku": 12345},
+			Result:   "00012345|Tee...|**Ü**|abcdef",
+		},
+		"truncate": {
+			Template: `{{ "Größenverstellbarer Bürostuhl" | truncate 22 }}|{{ "Größenverstellbarer Bürostuhl" | truncate 22 "words" "ellipsis=..." }}|{{ "short" | truncate 10 }}`,
+			Result:   "Größenverstellbarer B…|Größenverstellbarer...|short",
+		},
+		"wordwrap": {
+			Template: `{{ "1 Long Road, Little Snoring, Fakenham, Norfolk NR21 0AA" | wordwrap 20 "  " }}`,
+			Result:   "1 Long Road, Little\n  Snoring, Fakenham,\n  Norfolk NR21 0AA",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+}
+
+func TestEastAsianWidth(t *testing.T) {
+	SetEastAsianWidth(true)
+	defer SetEastAsianWidth(false)
+	res, err := Template(`'{{ "日本語テキスト" | fixlen 5 }}'|'{{ "東京" | padLeft 6 }}'|{{ "日本語テキスト" | truncate 7 }}|'{{ "éx" | fixlen 3 }}'`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "'日本 '|'  東京'|日本語…|'éx '"; res != want {
+		t.Errorf("east asian width: %#v != %#v", res, want)
+	}
+}
@@ -0,0 +1,297 @@
+package gotemplate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// xmlOptions are the options of xmlWrite
+type xmlOptions struct {
+	root        string
+	item        string
+	cdata       string // never (escape), always or auto (when escaping is needed)
+	attrPrefix  string
+	textKey     string
+	namespaces  [][2]string
+	declaration bool
+	encoding    string
+	indent      string
+	order       []string
+}
+
+func defaultXMLOptions() xmlOptions {
+	return xmlOptions{item: "item", cdata: "never", attrPrefix: "-", textKey: "#text"}
+}
+
+// parseXMLOptions reads key=value options, see xmlWrite
+func parseXMLOptions(args []string) (xmlOptions, error) {
+	o := defaultXMLOptions()
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		key, val := kv[0], ""
+		if len(kv) == 2 {
+			val = kv[1]
+		}
+		switch {
+		case key == "root":
+			o.root = val
+		case key == "item":
+			o.item = val
+		case key == "cdata":
+			if val != "never" && val != "always" && val != "auto" {
+				return o, fmt.Errorf("unknown cdata policy %s, use never, always or auto", val)
+			}
+			o.cdata = val
+		case key == "attr":
+			o.attrPrefix = val
+		case key == "text":
+			o.textKey = val
+		case key == "ns":
+			o.namespaces = append(o.namespaces, [2]string{"xmlns", val})
+		case strings.HasPrefix(key, "ns:"):
+			o.namespaces = append(o.namespaces, [2]string{"xmlns:" + key[3:], val})
+		case key == "declaration":
+			o.declaration = true
+		case key == "encoding":
+			o.declaration, o.encoding = true, val
+		case key == "indent":
+			o.indent = "  "
+			if len(kv) == 2 {
+				o.indent = val
+			}
+		case key == "compact":
+			o.indent = ""
+		case key == "order":
+			o.order = strings.Split(val, ",")
+		default:
+			return o, fmt.Errorf("unknown option %s", arg)
+		}
+	}
+	return o, nil
+}
+
+var xmlEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;", `'`, "&apos;")
+
+// xmlWriter writes maps, slices and values as XML, "-name" keys (by default)
+// are attributes and "#text" the text of an element
+type xmlWriter struct {
+	opts xmlOptions
+	b    strings.Builder
+}
+
+func isXMLName(name string) bool {
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.' || r == ':'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// xmlText formats a value as element or attribute text
+func xmlText(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32), nil
+	case time.Time:
+		return val.Format(time.RFC3339), nil
+	case nil:
+		return "", nil
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return "", fmt.Errorf("can't write %T as XML", v)
+	}
+	return fmt.Sprint(v), nil
+}
+
+func (w *xmlWriter) text(s string) {
+	needsEscape := strings.ContainsAny(s, `&<>"'`)
+	if w.opts.cdata == "always" || w.opts.cdata == "auto" && needsEscape {
+		w.b.WriteString("<![CDATA[" + strings.Replace(s, "]]>", "]]]]><![CDATA[>", -1) + "]]>")
+		return
+	}
+	w.b.WriteString(xmlEscaper.Replace(s))
+}
+
+// keys orders the keys of m by the order option, the rest alphabetically
+func (w *xmlWriter) keys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	rank := map[string]int{}
+	for i, k := range w.opts.order {
+		rank[k] = i + 1
+	}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := rank[keys[i]], rank[keys[j]]
+		if ri > 0 && rj > 0 {
+			return ri < rj
+		}
+		if ri > 0 || rj > 0 {
+			return ri > 0
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func (w *xmlWriter) newline(depth int) {
+	if w.opts.indent != "" && w.b.Len() > 0 {
+		w.b.WriteString("\n" + strings.Repeat(w.opts.indent, depth))
+	}
+}
+
+// element writes v as name element(s), slices repeat the element
+func (w *xmlWriter) element(name string, v interface{}, depth int, attrs [][2]string) error {
+	if items, ok := toSlice(v); ok {
+		for _, item := range items {
+			if err := w.element(name, item, depth, attrs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !isXMLName(name) {
+		return fmt.Errorf("invalid element name %q", name)
+	}
+	m, isMap := toMap(v)
+	text, children := "", []string{}
+	if isMap {
+		for _, k := range w.keys(m) {
+			switch {
+			case k == w.opts.textKey:
+				t, err := xmlText(m[k])
+				if err != nil {
+					return err
+				}
+				text = t
+			case w.opts.attrPrefix != "" && strings.HasPrefix(k, w.opts.attrPrefix):
+				t, err := xmlText(m[k])
+				if err != nil {
+					return err
+				}
+				attrs = append(attrs, [2]string{strings.TrimPrefix(k, w.opts.attrPrefix), t})
+			default:
+				children = append(children, k)
+			}
+		}
+	} else {
+		t, err := xmlText(v)
+		if err != nil {
+			return err
+		}
+		text = t
+	}
+	w.newline(depth)
+	w.b.WriteString("<" + name)
+	for _, a := range attrs {
+		if !isXMLName(a[0]) {
+			return fmt.Errorf("invalid attribute name %q", a[0])
+		}
+		w.b.WriteString(" " + a[0] + `="` + xmlEscaper.Replace(a[1]) + `"`)
+	}
+	if text == "" && len(children) == 0 {
+		w.b.WriteString("/>")
+		return nil
+	}
+	w.b.WriteString(">")
+	w.text(text)
+	for _, k := range children {
+		if err := w.element(k, m[k], depth+1, nil); err != nil {
+			return err
+		}
+	}
+	if len(children) > 0 {
+		w.newline(depth)
+	}
+	w.b.WriteString("</" + name + ">")
+	return nil
+}
+
+// write writes v as XML document: slices in a root (default items) of item
+// elements, maps in the root or as their single key, values as text
+func (w *xmlWriter) write(v interface{}) (string, error) {
+	if w.opts.declaration {
+		w.b.WriteString(`<?xml version="1.0"`)
+		if w.opts.encoding != "" {
+			w.b.WriteString(` encoding="` + xmlEscaper.Replace(w.opts.encoding) + `"`)
+		}
+		w.b.WriteString("?>")
+	}
+	var err error
+	m, isMap := toMap(v)
+	items, isSlice := toSlice(v)
+	switch {
+	case isSlice:
+		root := w.opts.root
+		if root == "" {
+			root = "items"
+		}
+		err = w.element(root, map[string]interface{}{w.opts.item: items}, 0, w.opts.namespaces)
+	case isMap && w.opts.root == "" && len(m) == 1:
+		for k := range m {
+			err = w.element(k, m[k], 0, w.opts.namespaces)
+		}
+	case isMap:
+		root := w.opts.root
+		if root == "" {
+			root = "doc"
+		}
+		err = w.element(root, m, 0, w.opts.namespaces)
+	case w.opts.root != "":
+		err = w.element(w.opts.root, v, 0, w.opts.namespaces)
+	default:
+		var t string
+		if t, err = xmlText(v); err == nil {
+			w.text(t)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return w.b.String(), nil
+}
+
+// xmlWrite writes a value as XML with options, the value is the last
+// argument:
+//
+//	root=products, item=product: root and item element names of lists
+//	cdata=never|always|auto: escape text (default), use CDATA, or CDATA when
+//	  escaping is needed
+//	attr=@, text=_: attribute key prefix (default "-") and text key
+//	  (default "#text")
+//	ns=uri, ns:g=uri: default and prefixed namespace declarations on the root
+//	declaration, encoding=UTF-8: add the XML declaration
+//	indent, indent=<tab>, compact: indent (two spaces) or not (default)
+//	order=sku,name,price: elements in this order, the rest alphabetically
+//
+// xmlWrite "root=feed" "ns:g=http://base.google.com/ns/1.0" "indent" .
+func xmlWrite(args ...interface{}) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("xmlWrite: expected [options] value")
+	}
+	opts, err := parseXMLOptions(stringArgs(args[:len(args)-1]))
+	if err != nil {
+		return "", fmt.Errorf("xmlWrite: %v", err)
+	}
+	w := &xmlWriter{opts: opts}
+	res, err := w.write(args[len(args)-1])
+	if err != nil {
+		return "", fmt.Errorf("xmlWrite: %v", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,84 @@
+package gotemplate
+
+import "testing"
+
+func TestXMLWrite(t *testing.T) {
+	product := map[string]interface{}{
+		"sku":   "A-1",
+		"name":  "Fish & Chips",
+		"price": 4.5,
+		"-id":   7,
+	}
+	tests := map[string]testTemplateStruct{
+		"compact": {
+			Template: `{{xmlWrite "root=product" .v}}`,
+			Values:   map[string]interface{}{"v": product},
+			Result:   `<product id="7"><name>Fish &amp; Chips</name><price>4.5</price><sku>A-1</sku></product>`,
+		},
+		"order and indent": {
+			Template: `{{xmlWrite "root=product" "order=sku,price" "indent" .v}}`,
+			Values:   map[string]interface{}{"v": product},
+			Result:   "<product id=\"7\">\n  <sku>A-1</sku>\n  <price>4.5</price>\n  <name>Fish &amp; Chips</name>\n</product>",
+		},
+		"cdata always": {
+			Template: `{{xmlWrite "cdata=always" .v}}`,
+			Values:   map[string]interface{}{"v": map[string]interface{}{"note": "a]]>b"}},
+			Result:   `<note><![CDATA[a]]]]><![CDATA[>b]]></note>`,
+		},
+		"cdata auto": {
+			Template: `{{xmlWrite "root=r" "cdata=auto" .v}}`,
+			Values:   map[string]interface{}{"v": map[string]interface{}{"a": "<b>", "c": "d"}},
+			Result:   `<r><a><![CDATA[<b>]]></a><c>d</c></r>`,
+		},
+		"attributes and text": {
+			Template: `{{xmlWrite "attr=@" "text=_" .v}}`,
+			Values:   map[string]interface{}{"v": map[string]interface{}{"price": map[string]interface{}{"@currency": "GBP", "_": 9.99}}},
+			Result:   `<price currency="GBP">9.99</price>`,
+		},
+		"namespaces and declaration": {
+			Template: `{{xmlWrite "root=feed" "ns=http://www.w3.org/2005/Atom" "ns:g=http://base.google.com/ns/1.0" "encoding=UTF-8" .v}}`,
+			Values:   map[string]interface{}{"v": map[string]interface{}{"g:id": 1}},
+			Result:   `<?xml version="1.0" encoding="UTF-8"?><feed xmlns="http://www.w3.org/2005/Atom" xmlns:g="http://base.google.com/ns/1.0"><g:id>1</g:id></feed>`,
+		},
+		"list": {
+			Template: `{{xmlWrite "root=skus" "item=sku" "indent=\t" "declaration" .v}}`,
+			Values:   map[string]interface{}{"v": []string{"A", "B"}},
+			Result:   "<?xml version=\"1.0\"?>\n<skus>\n\t<sku>A</sku>\n\t<sku>B</sku>\n</skus>",
+		},
+		"empty and nil": {
+			Template: `{{xmlWrite "root=r" .v}}`,
+			Values:   map[string]interface{}{"v": map[string]interface{}{"a": nil, "b": ""}},
+			Result:   `<r><a/><b/></r>`,
+		},
+		"value": {
+			Template: `{{xmlWrite .v}}`,
+			Values:   map[string]interface{}{"v": "a & b"},
+			Result:   `a &amp; b`,
+		},
+	}
+
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if res != test.Result {
+			t.Errorf("%s: '%s' != '%s'", name, res, test.Result)
+		}
+	}
+
+	errors := map[string]testTemplateStruct{
+		"unknown option": {Template: `{{xmlWrite "pretty" .v}}`, Values: map[string]interface{}{"v": 1}},
+		"cdata policy":   {Template: `{{xmlWrite "cdata=sometimes" .v}}`, Values: map[string]interface{}{"v": 1}},
+		"element name":   {Template: `{{xmlWrite .v}}`, Values: map[string]interface{}{"v": map[string]interface{}{"1st": 1}}},
+		"value":          {Template: `{{xmlWrite "root=r" .v}}`, Values: map[string]interface{}{"v": map[string]interface{}{"f": func() {}}}},
+		"encode":         {Template: `{{xml_encode .v}}`, Values: map[string]interface{}{"v": map[string]interface{}{"a b": 1}}},
+		"array":          {Template: `{{xml_array .v "items" "item"}}`, Values: map[string]interface{}{"v": "not a list"}},
+	}
+	for name, test := range errors {
+		if _, err := Template(test.Template, test.Values); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
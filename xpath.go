@@ -0,0 +1,178 @@
+package gotemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/shoobyban/mxj"
+)
+
+// XMLNode is an element returned by an XPath query. It prints as its text
+// content and can be passed to xpath again for relative queries.
+type XMLNode struct {
+	*xmlquery.Node
+}
+
+// String returns the text content of the node
+func (n XMLNode) String() string {
+	return n.InnerText()
+}
+
+// Attr returns the value of the named attribute, or "" when missing
+func (n XMLNode) Attr(name string) string {
+	return n.SelectAttr(name)
+}
+
+// XML returns the node and its children as XML
+func (n XMLNode) XML() string {
+	return n.OutputXML(true)
+}
+
+// XPath evaluates an XPath 1.0 expression against data, which can be a raw
+// XML string or []byte, a map produced by xml_decode or an XMLNode.
+// Node-set results are returned as []interface{} holding an XMLNode for each
+// element and a string for each attribute or text node; numeric, boolean and
+// string results (count(), contains(), ...) are returned as they are.
+//
+// Maps don't keep the order of differently named siblings: their elements
+// are built in alphabetical order of name, so positions and sibling axes
+// are only reliable among elements of the same name, e.g. book[2] but not
+// *[2] or following-sibling::*. Query the XML string when order matters.
+func XPath(data interface{}, expr string) (result interface{}, err error) {
+	doc, err := xmlDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	e, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("xpath: invalid expression '%s': %v", expr, err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("xpath: can't evaluate '%s': %v", expr, r)
+		}
+	}()
+	res := e.Evaluate(xmlquery.CreateXPathNavigator(doc))
+	iter, ok := res.(*xpath.NodeIterator)
+	if !ok {
+		return res, nil
+	}
+	nodes := []interface{}{}
+	for iter.MoveNext() {
+		nav := iter.Current().(*xmlquery.NodeNavigator)
+		if nav.NodeType() == xpath.AttributeNode {
+			nodes = append(nodes, nav.Value())
+			continue
+		}
+		nodes = append(nodes, xmlNodeValue(nav.Current()))
+	}
+	return nodes, nil
+}
+
+// XPathOne is like XPath but returns only the first node of a node-set,
+// or nil when nothing matched
+func XPathOne(data interface{}, expr string) (interface{}, error) {
+	res, err := XPath(data, expr)
+	if err != nil {
+		return nil, err
+	}
+	if nodes, ok := res.([]interface{}); ok {
+		if len(nodes) == 0 {
+			return nil, nil
+		}
+		return nodes[0], nil
+	}
+	return res, nil
+}
+
+func xmlNodeValue(n *xmlquery.Node) interface{} {
+	switch n.Type {
+	case xmlquery.ElementNode, xmlquery.DocumentNode:
+		return XMLNode{n}
+	}
+	return n.InnerText()
+}
+
+// xmlDocument turns supported inputs into a queryable node tree
+func xmlDocument(data interface{}) (*xmlquery.Node, error) {
+	switch d := data.(type) {
+	case XMLNode:
+		return d.Node, nil
+	case *xmlquery.Node:
+		return d, nil
+	case string:
+		return parseXMLDocument([]byte(d))
+	case []byte:
+		return parseXMLDocument(d)
+	case mxj.Map:
+		return mapDocument(d), nil
+	case map[string]interface{}:
+		return mapDocument(d), nil
+	case nil:
+		return nil, errors.New("xpath: no data")
+	}
+	return nil, fmt.Errorf("xpath: unsupported data type %T", data)
+}
+
+func parseXMLDocument(b []byte) (*xmlquery.Node, error) {
+	doc, err := xmlquery.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("xpath: can't parse xml: %v", err)
+	}
+	return doc, nil
+}
+
+// mapDocument builds a node tree from an mxj style map, where "-name" keys
+// are attributes and "#text" holds the element text. Sibling elements are
+// added in alphabetical order of name, lists in their own order.
+func mapDocument(m map[string]interface{}) *xmlquery.Node {
+	doc := &xmlquery.Node{Type: xmlquery.DocumentNode}
+	appendMapNodes(doc, m)
+	return doc
+}
+
+func appendMapNodes(parent *xmlquery.Node, m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch {
+		case k == "#text":
+			xmlquery.AddChild(parent, &xmlquery.Node{Type: xmlquery.TextNode, Data: fmt.Sprint(m[k])})
+		case strings.HasPrefix(k, "-") && parent.Type == xmlquery.ElementNode:
+			xmlquery.AddAttr(parent, k[1:], fmt.Sprint(m[k]))
+		default:
+			appendElements(parent, k, m[k])
+		}
+	}
+}
+
+func appendElements(parent *xmlquery.Node, name string, v interface{}) {
+	if items, ok := v.([]interface{}); ok {
+		for _, item := range items {
+			appendElements(parent, name, item)
+		}
+		return
+	}
+	el := &xmlquery.Node{Type: xmlquery.ElementNode, Data: name}
+	if i := strings.Index(name, ":"); i > 0 {
+		el.Prefix, el.Data = name[:i], name[i+1:]
+	}
+	xmlquery.AddChild(parent, el)
+	switch val := v.(type) {
+	case map[string]interface{}:
+		appendMapNodes(el, val)
+	case mxj.Map:
+		appendMapNodes(el, val)
+	case nil:
+	default:
+		xmlquery.AddChild(el, &xmlquery.Node{Type: xmlquery.TextNode, Data: fmt.Sprint(val)})
+	}
+}
@@ -0,0 +1,77 @@
+package gotemplate
+
+import "testing"
+
+const testXPathXML = `<?xml version="1.0"?>
+<library>
+  <book id="b1" lang="en"><title>Go Templates</title><price>12.50</price></book>
+  <book id="b2" lang="de"><title>Vorlagen</title><price>9.99</price></book>
+  <book id="b3" lang="en"><title>XPath Basics</title><price>20</price></book>
+</library>`
+
+func TestXPath(t *testing.T) {
+	tests := map[string]testTemplateStruct{
+		"string": {
+			Template: `{{ range xpath .xml "//book[@lang='en']/title" }}{{.}};{{ end }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "Go Templates;XPath Basics;",
+		},
+		"attribute": {
+			Template: `{{ xpathOne .xml "//book[2]/@id" }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "b2",
+		},
+		"position": {
+			Template: `{{ xpathOne .xml "(//book)[last()]/title" }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "XPath Basics",
+		},
+		"count": {
+			Template: `{{ xpathOne .xml "count(//book[contains(title, 'Go')])" }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "1",
+		},
+		"parent": {
+			Template: `{{ (xpathOne .xml "//title[.='Vorlagen']/..").Attr "lang" }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "de",
+		},
+		"relative": {
+			Template: `{{ range xpath .xml "//book[price > 10]" }}{{ xpathOne . "title" }}={{ .Attr "id" }};{{ end }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "Go Templates=b1;XPath Basics=b3;",
+		},
+		"xml_decode": {
+			Template: `{{ $d := xml_decode .xml }}{{ range xpath $d "/library/book[@lang='de']" }}{{ xpathOne . "price" }}{{ end }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "9.99",
+		},
+		"xml_decode order": {
+			Template: `{{ $d := xml_decode .xml }}{{ xpathOne $d "/root/*[1]" }}{{ xpathOne .xml "/root/*[1]" }}|{{ xpathOne $d "/root/b[2]" }}{{ xpathOne .xml "/root/b[2]" }}`,
+			Values:   map[string]interface{}{"xml": `<root><b>1</b><a>2</a><b>3</b></root>`},
+			Result:   "21|33",
+		},
+		"attributes": {
+			Template: `{{ range xpath .xml "//book/@id" }}{{.}};{{ end }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "b1;b2;b3;",
+		},
+		"no match": {
+			Template: `{{ xpathOne .xml "//magazine" }}|{{ len (xpath .xml "//magazine") }}`,
+			Values:   map[string]interface{}{"xml": testXPathXML},
+			Result:   "|0",
+		},
+	}
+	for name, test := range tests {
+		res, err := Template(test.Template, test.Values)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if res != test.Result {
+			t.Errorf("%s: %#v != %#v", name, res, test.Result)
+		}
+	}
+	if _, err := XPath(testXPathXML, "//book["); err == nil {
+		t.Errorf("invalid expression: expected error")
+	}
+}